@@ -0,0 +1,216 @@
+package errors
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// attributes is an immutable linked list holding the attributes of an error, the most recent first.
+// Builders prepend to the list of the error they derive from, so that deriving an error never copies
+// its attributes. A more recent attribute shadows older ones with the same key.
+type attributes struct {
+	key   string
+	value any
+	next  *attributes
+	size  int
+}
+
+// attributesFromMap returns a list holding the entries of data, sorted by key.
+func attributesFromMap(data map[string]any) *attributes {
+	var result *attributes
+
+	for _, key := range slices.Backward(slices.Sorted(maps.Keys(data))) {
+		result = result.with(key, data[key])
+	}
+
+	return result
+}
+
+// with returns a list holding an additional attribute.
+func (a *attributes) with(key string, value any) *attributes {
+	return &attributes{key: key, value: value, next: a, size: a.len() + 1}
+}
+
+// get returns the most recent value of the attribute with the given key.
+func (a *attributes) get(key string) (any, bool) {
+	for n := a; n != nil; n = n.next {
+		if n.key == key {
+			return n.value, true
+		}
+	}
+
+	return nil, false
+}
+
+// len returns the number of entries of the list, an upper bound of the number of attributes.
+func (a *attributes) len() int {
+	if a == nil {
+		return 0
+	}
+
+	return a.size
+}
+
+// all iterates over the attributes, yielding the most recent value of each key.
+func (a *attributes) all() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for n := a; n != nil; n = n.next {
+			if a.shadowed(n) {
+				continue
+			}
+
+			if !yield(n.key, n.value) {
+				return
+			}
+		}
+	}
+}
+
+// shadowed returns true if an entry of a that is more recent than n has the same key.
+func (a *attributes) shadowed(n *attributes) bool {
+	for m := a; m != n; m = m.next {
+		if m.key == n.key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Get returns the value of the attribute with the given key, as it was set on the error.
+func (e *AsertoError) Get(key string) (any, bool) {
+	value, ok := e.data.get(key)
+
+	return unwrapValue(value), ok
+}
+
+// GetStr returns the attribute with the given key formatted as a string.
+func (e *AsertoError) GetStr(key string) (string, bool) {
+	value, ok := e.data.get(key)
+	if !ok {
+		return "", false
+	}
+
+	return formatValue(value), true
+}
+
+// GetInt returns the attribute with the given key as an int.
+// Attributes received in a gRPC status are parsed from their string representation.
+func (e *AsertoError) GetInt(key string) (int, bool) {
+	value, ok := e.GetInt64(key)
+	if !ok || int64(int(value)) != value {
+		return 0, false
+	}
+
+	return int(value), true
+}
+
+// GetInt32 returns the attribute with the given key as an int32.
+// Attributes received in a gRPC status are parsed from their string representation.
+func (e *AsertoError) GetInt32(key string) (int32, bool) {
+	value, ok := e.GetInt64(key)
+	if !ok || int64(int32(value)) != value {
+		return 0, false
+	}
+
+	return int32(value), true
+}
+
+// GetInt64 returns the attribute with the given key as an int64.
+// Attributes received in a gRPC status are parsed from their string representation.
+func (e *AsertoError) GetInt64(key string) (int64, bool) {
+	switch value := unwrapValue(e.attribute(key)).(type) {
+	case int:
+		return int64(value), true
+	case int32:
+		return int64(value), true
+	case int64:
+		return value, true
+	case string:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+
+		return parsed, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// GetBool returns the attribute with the given key as a bool.
+// Attributes received in a gRPC status are parsed from their string representation.
+func (e *AsertoError) GetBool(key string) (bool, bool) {
+	switch value := unwrapValue(e.attribute(key)).(type) {
+	case bool:
+		return value, true
+	case string:
+		parsed, err := strconv.ParseBool(value)
+
+		return parsed, err == nil
+	default:
+		return false, false
+	}
+}
+
+// GetDuration returns the attribute with the given key as a time.Duration.
+// Attributes received in a gRPC status are parsed from their string representation.
+func (e *AsertoError) GetDuration(key string) (time.Duration, bool) {
+	switch value := unwrapValue(e.attribute(key)).(type) {
+	case time.Duration:
+		return value, true
+	case string:
+		parsed, err := time.ParseDuration(value)
+
+		return parsed, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// GetTime returns the attribute with the given key as a time.Time.
+// Attributes received in a gRPC status are parsed from their RFC 3339 representation.
+func (e *AsertoError) GetTime(key string) (time.Time, bool) {
+	switch value := unwrapValue(e.attribute(key)).(type) {
+	case time.Time:
+		return value, true
+	case string:
+		parsed, err := time.Parse(time.RFC3339, value)
+
+		return parsed, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+// attribute returns the value of the attribute with the given key, or nil.
+func (e *AsertoError) attribute(key string) any {
+	value, _ := e.data.get(key)
+
+	return value
+}
+
+// formatValue returns the string representation of an attribute value, used in gRPC status metadata.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Duration:
+		return v.String()
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case sensitiveValue:
+		return formatValue(v.value)
+	default:
+		return fmt.Sprintf("%+v", v)
+	}
+}
@@ -0,0 +1,181 @@
+package errors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+type point struct {
+	X int
+	Y int
+}
+
+var attributesTime = time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+
+func attributesError() *cerr.AsertoError {
+	return ErrNotFound.
+		Str("name", "alice").
+		Int("count", 3).
+		Int32("count32", 32).
+		Int64("count64", 64).
+		Bool("enabled", true).
+		Duration("elapsed", 1500*time.Millisecond).
+		Time("at", attributesTime).
+		Interface("point", point{X: 1, Y: 2})
+}
+
+func TestTypedGetters(t *testing.T) {
+	assert := require.New(t)
+
+	err := attributesError()
+
+	name, ok := err.GetStr("name")
+	assert.True(ok)
+	assert.Equal("alice", name)
+
+	count, ok := err.GetInt("count")
+	assert.True(ok)
+	assert.Equal(3, count)
+
+	count32, ok := err.GetInt32("count32")
+	assert.True(ok)
+	assert.Equal(int32(32), count32)
+
+	count64, ok := err.GetInt64("count64")
+	assert.True(ok)
+	assert.Equal(int64(64), count64)
+
+	enabled, ok := err.GetBool("enabled")
+	assert.True(ok)
+	assert.True(enabled)
+
+	elapsed, ok := err.GetDuration("elapsed")
+	assert.True(ok)
+	assert.Equal(1500*time.Millisecond, elapsed)
+
+	at, ok := err.GetTime("at")
+	assert.True(ok)
+	assert.Equal(attributesTime, at)
+
+	value, ok := err.Get("point")
+	assert.True(ok)
+	assert.Equal("{X:1 Y:2}", value)
+
+	_, ok = err.GetInt("name")
+	assert.False(ok)
+
+	_, ok = err.GetBool("missing")
+	assert.False(ok)
+}
+
+func TestTypedGettersAfterGRPCRoundTrip(t *testing.T) {
+	assert := require.New(t)
+
+	received := roundTrip(t, attributesError())
+
+	count, ok := received.GetInt("count")
+	assert.True(ok)
+	assert.Equal(3, count)
+
+	enabled, ok := received.GetBool("enabled")
+	assert.True(ok)
+	assert.True(enabled)
+
+	elapsed, ok := received.GetDuration("elapsed")
+	assert.True(ok)
+	assert.Equal(1500*time.Millisecond, elapsed)
+
+	at, ok := received.GetTime("at")
+	assert.True(ok)
+	assert.True(attributesTime.Equal(at))
+}
+
+func TestDataIsStringified(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(map[string]string{
+		"name":    "alice",
+		"count":   "3",
+		"count32": "32",
+		"count64": "64",
+		"enabled": "true",
+		"elapsed": "1.5s",
+		"at":      "2024-03-01T12:30:00Z",
+		"point":   "{X:1 Y:2}",
+	}, attributesError().Data())
+}
+
+func TestFieldsAreTyped(t *testing.T) {
+	assert := require.New(t)
+
+	fields := attributesError().Fields()
+	assert.Equal(3, fields["count"])
+	assert.Equal(true, fields["enabled"])
+	assert.Equal(1500*time.Millisecond, fields["elapsed"])
+	assert.Equal(attributesTime, fields["at"])
+}
+
+func TestMarshalZerologObjectTypes(t *testing.T) {
+	assert := require.New(t)
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf)
+	logger.Error().EmbedObject(attributesError()).Send()
+
+	entry := map[string]any{}
+	assert.NoError(json.Unmarshal(buf.Bytes(), &entry))
+
+	assert.Equal("alice", entry["name"])
+	assert.InDelta(3, entry["count"], 0)
+	assert.InDelta(32, entry["count32"], 0)
+	assert.InDelta(64, entry["count64"], 0)
+	assert.Equal(true, entry["enabled"])
+	assert.InDelta(1500, entry["elapsed"], 0)
+	assert.Equal("{X:1 Y:2}", entry["point"])
+}
+
+func TestInterfaceFormatsReferenceValues(t *testing.T) {
+	assert := require.New(t)
+
+	tags := map[string]string{"env": "prod"}
+	ids := []int{1, 2}
+	p := &point{X: 1, Y: 2}
+
+	err := ErrNotFound.Interface("tags", tags).Interface("ids", ids).Interface("point", p)
+
+	tags["env"] = "dev"
+	ids[0] = 3
+	p.X = 5
+
+	assert.Equal("map[env:prod]", err.Data()["tags"])
+	assert.Equal("[1 2]", err.Data()["ids"])
+	assert.Equal("&{X:1 Y:2}", err.Data()["point"])
+	assert.Equal("map[env:prod]", err.Fields()["tags"])
+}
+
+func TestBuildersShareAttributes(t *testing.T) {
+	assert := require.New(t)
+
+	parent := ErrNotFound.Str("object_id", "1234").Str("object_type", "user")
+	first := parent.Str("object_id", "5678").Msg("first")
+	second := parent.Int("depth", 2).Msg("second")
+
+	assert.Equal(map[string]string{"object_id": "1234", "object_type": "user"}, parent.Data())
+	assert.Equal(map[string]string{"object_id": "5678", "object_type": "user", "msg": "first"}, first.Data())
+	assert.Equal(map[string]string{"object_id": "1234", "object_type": "user", "depth": "2", "msg": "second"}, second.Data())
+	assert.Empty(ErrNotFound.Data())
+
+	assert.Len(first.Fields(), 3)
+	assert.Equal("5678", first.Fields()["object_id"])
+	assert.Equal("E10001 not found: first: again", first.Msg("again").Error())
+
+	received := roundTrip(t, first)
+	assert.Equal(first.Data(), received.Data())
+	assert.Equal("5678", received.Str("object_type", "group").Data()["object_id"])
+}
@@ -0,0 +1,96 @@
+package errors_test
+
+import (
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/pkg/errors"
+)
+
+func benchmarkError() *cerr.AsertoError {
+	return ErrNotFound.
+		Str("object_type", "user").
+		Str("object_id", "1234").
+		Str("relation", "member").
+		Str("subject_type", "group").
+		Str("subject_id", "admins").
+		Int("depth", 3).
+		Bool("cached", false).
+		Msg("failed to resolve relation")
+}
+
+func BenchmarkBuilderChain(b *testing.B) {
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = benchmarkError()
+	}
+}
+
+func BenchmarkBuilderChainWithCauses(b *testing.B) {
+	inner := errors.New("boom")
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = ErrNotFound.Str("object_id", "1234").Err(ErrAlreadyExists.Str("key", "value").Err(inner)).Msg("lookup failed")
+	}
+}
+
+func BenchmarkBuilderSingleAttribute(b *testing.B) {
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = ErrNotFound.Str("object_id", "1234")
+	}
+}
+
+func BenchmarkError(b *testing.B) {
+	err := benchmarkError().Err(ErrAlreadyExists.Msg("conflict")).Err(errors.New("boom"))
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = err.Error()
+	}
+}
+
+func BenchmarkErrorTemplate(b *testing.B) {
+	err := ErrObjectTypeNotFound.Str("object_id", "1234").Str("object_type", "user")
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = err.Error()
+	}
+}
+
+func BenchmarkFields(b *testing.B) {
+	err := benchmarkError().Err(ErrAlreadyExists.Str("key", "value"))
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = err.Fields()
+	}
+}
+
+func BenchmarkGRPCStatus(b *testing.B) {
+	err := benchmarkError().Err(ErrAlreadyExists.Str("key", "value"))
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = err.GRPCStatus()
+	}
+}
+
+func BenchmarkFromGRPCStatus(b *testing.B) {
+	st := benchmarkError().Err(ErrAlreadyExists.Str("key", "value")).GRPCStatus()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = cerr.FromGRPCStatus(*st)
+	}
+}
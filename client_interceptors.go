@@ -0,0 +1,145 @@
+package errors
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// PeerKey is the name of the field holding the address of the server that returned an error.
+const PeerKey = "peer"
+
+// ClientInterceptorOption configures the interceptors returned by UnaryClientInterceptor and StreamClientInterceptor.
+type ClientInterceptorOption func(*clientInterceptorOptions)
+
+type clientInterceptorOptions struct {
+	registry *Registry
+}
+
+// WithClientRegistry looks the codes of the statuses returned by the server up in r instead of the default registry.
+func WithClientRegistry(r *Registry) ClientInterceptorOption {
+	return func(o *clientInterceptorOptions) {
+		o.registry = r
+	}
+}
+
+func newClientInterceptorOptions(opts []ClientInterceptorOption) *clientInterceptorOptions {
+	o := &clientInterceptorOptions{registry: defaultRegistry}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that converts the statuses returned
+// by the server back into AsertoErrors.
+func UnaryClientInterceptor(opts ...ClientInterceptorOption) grpc.UnaryClientInterceptor {
+	o := newClientInterceptorOptions(opts)
+
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		p := &peer.Peer{}
+
+		if err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Peer(p))...); err != nil {
+			return o.registry.clientError(ctx, method, p, err)
+		}
+
+		return nil
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor that converts the statuses returned
+// by the server back into AsertoErrors.
+func StreamClientInterceptor(opts ...ClientInterceptorOption) grpc.StreamClientInterceptor {
+	o := newClientInterceptorOptions(opts)
+
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		p := &peer.Peer{}
+
+		stream, err := streamer(ctx, desc, cc, method, append(opts, grpc.Peer(p))...)
+		if err != nil {
+			return nil, o.registry.clientError(ctx, method, p, err)
+		}
+
+		return &clientStream{ClientStream: stream, ctx: ctx, method: method, peer: p, registry: o.registry}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+
+	ctx      context.Context //nolint:containedctx
+	method   string
+	peer     *peer.Peer
+	registry *Registry
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	return s.convert(s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	return s.convert(s.ClientStream.RecvMsg(m))
+}
+
+func (s *clientStream) CloseSend() error {
+	return s.convert(s.ClientStream.CloseSend())
+}
+
+func (s *clientStream) convert(err error) error {
+	if err == nil || errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return s.registry.clientError(s.ctx, s.method, s.peer, err)
+}
+
+// clientError converts the gRPC status error err into the AsertoError it carries,
+// adding the method and the address of the server to its data.
+// Errors caused by the cancellation of ctx keep the context error in their chain.
+func (r *Registry) clientError(ctx context.Context, method string, p *peer.Peer, err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() == codes.OK {
+		return err
+	}
+
+	var aerr *AsertoError
+	if len(st.Details()) > 0 {
+		aerr = r.FromGRPCStatus(*st)
+	}
+
+	if aerr == nil {
+		aerr = fromPlainStatus(st)
+	}
+
+	aerr = aerr.Str(MethodKey, method)
+
+	if p.Addr != nil {
+		aerr = aerr.Str(PeerKey, p.Addr.String())
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil && (st.Code() == codes.Canceled || st.Code() == codes.DeadlineExceeded) {
+		aerr = aerr.Err(ctxErr)
+	}
+
+	return aerr
+}
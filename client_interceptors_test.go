@@ -0,0 +1,109 @@
+package errors_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func withClientInterceptors() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithUnaryInterceptor(cerr.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(cerr.StreamClientInterceptor()),
+	}
+}
+
+func TestClientInterceptorsAsertoError(t *testing.T) {
+	assert := require.New(t)
+
+	sent := ErrNotFound.Str("object_id", "1234").Msg("missing object")
+	conn := newTestConn(t, func(context.Context) error {
+		return sent
+	}, withServerInterceptors(), withClientInterceptors()...)
+
+	unaryErr, streamErr := callTestService(t, conn)
+
+	for method, err := range map[string]error{testUnaryMethod: unaryErr, testStreamMethod: streamErr} {
+		var received *cerr.AsertoError
+		assert.True(errors.As(err, &received))
+
+		assert.True(ErrNotFound.SameAs(err))
+		assert.True(cerr.Equals(sent, err))
+		assert.Equal(codes.NotFound, status.Code(err))
+		assert.Equal(http.StatusNotFound, received.HTTPCode)
+		assert.Equal(sent.Error(), received.Error())
+		assert.Equal("1234", received.Data()["object_id"])
+		assert.Equal(method, received.Data()[cerr.MethodKey])
+		assert.Equal("bufconn", received.Data()[cerr.PeerKey])
+	}
+}
+
+func TestClientInterceptorsPlainStatus(t *testing.T) {
+	assert := require.New(t)
+
+	conn := newTestConn(t, func(context.Context) error {
+		return status.Error(codes.PermissionDenied, "denied")
+	}, nil, withClientInterceptors()...)
+
+	unaryErr, streamErr := callTestService(t, conn)
+
+	for _, err := range []error{unaryErr, streamErr} {
+		assert.True(cerr.ErrUnknown.SameAs(err))
+		assert.Equal(codes.PermissionDenied, status.Code(err))
+		assert.Equal("E00000 an unknown error has occurred: denied", err.Error())
+	}
+}
+
+func TestClientInterceptorsCanceled(t *testing.T) {
+	assert := require.New(t)
+
+	conn := newTestConn(t, func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}, withServerInterceptors(), withClientInterceptors()...)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	err := conn.Invoke(ctx, testUnaryMethod, &emptypb.Empty{}, &emptypb.Empty{})
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Equal(codes.DeadlineExceeded, status.Code(err))
+
+	ctx, cancel = context.WithCancel(t.Context())
+
+	stream, err := conn.NewStream(ctx, testStreamDesc, testStreamMethod)
+	assert.NoError(err)
+	assert.NoError(stream.CloseSend())
+	assert.NoError(stream.RecvMsg(&emptypb.Empty{}))
+
+	cancel()
+
+	err = stream.RecvMsg(&emptypb.Empty{})
+	assert.ErrorIs(err, context.Canceled)
+	assert.Equal(codes.Canceled, status.Code(err))
+}
+
+func TestClientInterceptorsStreamEOF(t *testing.T) {
+	assert := require.New(t)
+
+	conn := newTestConn(t, func(context.Context) error {
+		return nil
+	}, withServerInterceptors(), withClientInterceptors()...)
+
+	stream, err := conn.NewStream(t.Context(), testStreamDesc, testStreamMethod)
+	assert.NoError(err)
+	assert.NoError(stream.CloseSend())
+	assert.NoError(stream.RecvMsg(&emptypb.Empty{}))
+	assert.Equal(io.EOF, stream.RecvMsg(&emptypb.Empty{}))
+}
@@ -39,3 +39,17 @@ func (ce *ContextError) Cause() error {
 func (ce *ContextError) Unwrap() error {
 	return ce.Err
 }
+
+// Is returns true if the wrapped error is a gRPC status carrying an AsertoError with the same code as target,
+// and that code is registered. Wrapped AsertoErrors are matched by errors.Is itself.
+// See AsertoError.Is for the gRPC statuses that are not wrapped in a ContextError.
+func (ce *ContextError) Is(target error) bool {
+	code, ok := errorCode(target)
+	if !ok {
+		return false
+	}
+
+	statusCode, ok := wrappedStatusCode(ce.Err)
+
+	return ok && statusCode == code
+}
@@ -2,10 +2,13 @@ package errors
 
 import (
 	"context"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/status"
@@ -15,6 +18,61 @@ const (
 	HTTPStatusErrorMetadata = "aserto-http-statuscode"
 )
 
+var defaultErrorHandler = NewErrorHandler() //nolint:gochecknoglobals
+
+// ErrorHandlerOption configures the error handler created by NewErrorHandler.
+type ErrorHandlerOption func(*errorHandler)
+
+// WithProblemDetails makes the error handler always respond with RFC 9457 application/problem+json bodies,
+// regardless of the Accept header of the request.
+func WithProblemDetails() ErrorHandlerOption {
+	return func(h *errorHandler) {
+		h.problemDetails = true
+	}
+}
+
+// WithProblemTypeBaseURI sets the URI prefix used to build the problem type of AsertoErrors
+// from their code. It defaults to DefaultProblemTypeBaseURI.
+func WithProblemTypeBaseURI(baseURI string) ErrorHandlerOption {
+	return func(h *errorHandler) {
+		h.problemTypeBaseURI = baseURI
+	}
+}
+
+// WithRegistry looks the codes of the gRPC statuses handled by the error handler up in r instead of the default registry.
+func WithRegistry(r *Registry) ErrorHandlerOption {
+	return func(h *errorHandler) {
+		h.registry = r
+	}
+}
+
+type errorHandler struct {
+	registry           *Registry
+	problemDetails     bool
+	problemTypeBaseURI string
+}
+
+// NewErrorHandler returns a runtime.ErrorHandlerFunc that behaves like CustomErrorHandler, configured with opts.
+func NewErrorHandler(opts ...ErrorHandlerOption) runtime.ErrorHandlerFunc {
+	h := &errorHandler{
+		registry:           defaultRegistry,
+		problemTypeBaseURI: DefaultProblemTypeBaseURI,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h.handle
+}
+
+// CustomErrorHandler is a runtime.ErrorHandlerFunc that responds with the HTTP status code
+// carried in the HTTPStatusErrorMetadata of the error, falling back to the status code
+// derived from the gRPC status code.
+// Requests accepting application/problem+json receive an RFC 9457 problem details body.
+// The translation of the message in the locale of the Accept-Language header is attached as errdetails.LocalizedMessage,
+// or used as the title of problem details. The retry delay of retryable errors is sent as the Retry-After header.
+// The error is reported to the metrics hook set with SetMetricsHook.
 func CustomErrorHandler(
 	ctx context.Context,
 	gtw *runtime.ServeMux,
@@ -22,15 +80,79 @@ func CustomErrorHandler(
 	httpResponseWriter http.ResponseWriter,
 	httpRequest *http.Request,
 	err error,
+) {
+	defaultErrorHandler(ctx, gtw, runtimeMarshaler, httpResponseWriter, httpRequest, err)
+}
+
+func (h *errorHandler) handle(
+	ctx context.Context,
+	gtw *runtime.ServeMux,
+	runtimeMarshaler runtime.Marshaler,
+	httpResponseWriter http.ResponseWriter,
+	httpRequest *http.Request,
+	err error,
 ) {
 	if err == nil {
 		runtime.DefaultHTTPErrorHandler(ctx, gtw, runtimeMarshaler, httpResponseWriter, httpRequest, err)
+
+		return
+	}
+
+	observeGatewayError(ctx, h.registry, err)
+
+	aerr := h.registry.gatewayAsertoError(err)
+	localized := requestLocalizedMessage(httpRequest, aerr)
+
+	if h.problemDetails || acceptsProblemDetails(httpRequest) {
+		h.writeProblem(ctx, gtw, httpResponseWriter, httpRequest, err, localized)
+
+		return
+	}
+
+	st := redactStatus(httpSink, convertStatus(httpSink, gatewayError(err)))
+	if aerr != nil {
+		st = renderStatus(st, aerr)
+	}
+
+	if localized != nil {
+		st = localizeStatus(st, localized)
+	}
+
+	setRetryAfter(httpResponseWriter, st)
+
+	if localized = statusLocalizedMessage(st); localized != nil {
+		httpResponseWriter.Header().Set("Content-Language", localized.GetLocale())
+	}
+
+	var httpStatusError runtime.HTTPStatusError
+
+	httpStatusError.Err = st.Err()
+	httpStatusError.HTTPStatus = httpStatus(ctx, err)
+	runtime.DefaultHTTPErrorHandler(ctx, gtw, runtimeMarshaler, httpResponseWriter, httpRequest, &httpStatusError)
+}
+
+// httpStatus returns the HTTP status code of the response for err, using the same rules as runtime.DefaultHTTPErrorHandler
+// but preferring the status code carried in the HTTPStatusErrorMetadata.
+func httpStatus(ctx context.Context, err error) int {
+	var httpStatusError *runtime.HTTPStatusError
+	if errors.As(err, &httpStatusError) {
+		return httpStatusError.HTTPStatus
 	}
 
 	st := status.Convert(err)
+	if code, ok := httpStatusFromMetadata(ctx, st); ok {
+		return code
+	}
+
+	return runtime.HTTPStatusFromCode(st.Code())
+}
+
+// httpStatusFromMetadata returns the HTTP status code found in the metadata of the ErrorInfo details of st.
+// The details describing inner errors are skipped.
+func httpStatusFromMetadata(ctx context.Context, st *status.Status) (int, bool) {
 	for _, detail := range st.Details() {
 		errInfo, isErrInfo := detail.(*errdetails.ErrorInfo)
-		if !isErrInfo {
+		if !isErrInfo || errInfo.GetReason() == causeReason {
 			continue
 		}
 
@@ -43,16 +165,52 @@ func CustomErrorHandler(
 		if conversionErr != nil {
 			logger := zerolog.Ctx(ctx)
 			logger.Error().Err(conversionErr).Msg("Failed to detect http status code associated with this AsertoError")
-		} else {
-			var httpStatusError runtime.HTTPStatusError
 
-			httpStatusError.Err = err
-			httpStatusError.HTTPStatus = code
-			runtime.DefaultHTTPErrorHandler(ctx, gtw, runtimeMarshaler, httpResponseWriter, httpRequest, &httpStatusError)
+			continue
+		}
+
+		return code, true
+	}
+
+	return 0, false
+}
+
+// renderStatus returns a copy of st whose message is the message of aerr rendered for HTTP responses.
+func renderStatus(st *status.Status, aerr *AsertoError) *status.Status {
+	pb := st.Proto()
+	pb.Message = aerr.message(httpSink)
+
+	return status.FromProto(pb)
+}
+
+// requestLocalizedMessage returns the translation of the message of aerr in the locale listed
+// in the Accept-Language header of r, or nil if there is none.
+func requestLocalizedMessage(r *http.Request, aerr *AsertoError) *errdetails.LocalizedMessage {
+	locale := LocaleFromRequest(r)
+	if locale == "" || aerr == nil {
+		return nil
+	}
+
+	return aerr.localizedMessage(httpSink, locale)
+}
+
+// acceptsProblemDetails returns true if the Accept header of the request lists application/problem+json
+// with a non-zero quality value.
+func acceptsProblemDetails(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for mediaRange := range strings.SplitSeq(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil || mediaType != ProblemJSONContentType {
+				continue
+			}
+
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q <= 0 {
+				continue
+			}
 
-			return
+			return true
 		}
 	}
 
-	runtime.DefaultHTTPErrorHandler(ctx, gtw, runtimeMarshaler, httpResponseWriter, httpRequest, err)
+	return false
 }
@@ -0,0 +1,249 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// serveError responds to every request through a gateway mux using handler to render err,
+// the error is sent as its gRPC status error, as it would be received from a backend service.
+func serveError(t *testing.T, handler runtime.ErrorHandlerFunc, err error, header http.Header) *http.Response {
+	t.Helper()
+
+	mux := runtime.NewServeMux(runtime.WithErrorHandler(handler))
+	require.NoError(t, mux.HandlePath(http.MethodGet, "/api/v1/objects", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		_, marshaler := runtime.MarshalerForRequest(mux, r)
+		runtime.HTTPError(r.Context(), mux, marshaler, w, r, err)
+	}))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	req, reqErr := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/api/v1/objects", http.NoBody)
+	require.NoError(t, reqErr)
+
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	resp, respErr := server.Client().Do(req)
+	require.NoError(t, respErr)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+func readBody(t *testing.T, resp *http.Response) map[string]any {
+	t.Helper()
+
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	body := map[string]any{}
+	require.NoError(t, json.Unmarshal(buf, &body))
+
+	return body
+}
+
+func TestGRPCStatusCarriesHTTPStatus(t *testing.T) {
+	assert := require.New(t)
+
+	metadata := func(err *cerr.AsertoError) map[string]string {
+		info, ok := err.GRPCStatus().Details()[0].(*errdetails.ErrorInfo)
+		assert.True(ok)
+
+		return info.GetMetadata()
+	}
+
+	assert.Equal("404", metadata(ErrNotFound)[cerr.HTTPStatusErrorMetadata])
+	assert.Equal("410", metadata(ErrNotFound.WithHTTPStatus(http.StatusGone))[cerr.HTTPStatusErrorMetadata])
+
+	received := cerr.FromGRPCStatus(*ErrNotFound.WithHTTPStatus(http.StatusGone).GRPCStatus())
+	assert.Equal(http.StatusGone, received.HTTPCode)
+	assert.NotContains(received.Data(), cerr.HTTPStatusErrorMetadata)
+}
+
+func TestCustomErrorHandlerUsesRegisteredHTTPStatus(t *testing.T) {
+	assert := require.New(t)
+
+	// codes.NotFound maps to 404, the registered status code must win.
+	aerr := cerr.NewAsertoError("E10003", codes.NotFound, http.StatusGone, "gone")
+
+	resp := serveError(t, cerr.CustomErrorHandler, aerr.GRPCStatus().Err(), nil)
+	assert.Equal(http.StatusGone, resp.StatusCode)
+
+	body := readBody(t, resp)
+	assert.InDelta(float64(codes.NotFound), body["code"], 0)
+	assert.Equal("gone", body["message"])
+}
+
+func TestCustomErrorHandlerUsesHTTPStatusOverride(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrNotFound.WithHTTPStatus(http.StatusTeapot).Msg("override").GRPCStatus().Err()
+
+	resp := serveError(t, cerr.CustomErrorHandler, err, nil)
+	assert.Equal(http.StatusTeapot, resp.StatusCode)
+}
+
+func TestCustomErrorHandlerExplicitMetadataWins(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrNotFound.Str(cerr.HTTPStatusErrorMetadata, strconv.Itoa(http.StatusConflict)).GRPCStatus().Err()
+
+	resp := serveError(t, cerr.CustomErrorHandler, err, nil)
+	assert.Equal(http.StatusConflict, resp.StatusCode)
+}
+
+func TestCustomErrorHandlerIgnoresInnerErrorStatus(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrNotFound.Err(ErrAlreadyExists.WithHTTPStatus(http.StatusTeapot)).GRPCStatus().Err()
+
+	resp := serveError(t, cerr.CustomErrorHandler, err, nil)
+	assert.Equal(http.StatusNotFound, resp.StatusCode)
+}
+
+func TestCustomErrorHandlerPlainStatus(t *testing.T) {
+	assert := require.New(t)
+
+	resp := serveError(t, cerr.CustomErrorHandler, status.Error(codes.PermissionDenied, "denied"), nil)
+	assert.Equal(http.StatusForbidden, resp.StatusCode)
+
+	resp = serveError(t, cerr.CustomErrorHandler, errors.New("boom"), nil)
+	assert.Equal(http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestCustomErrorHandlerProblemDetailsFromAccept(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrNotFound.WithHTTPStatus(http.StatusGone).Str("object_id", "1234").Msg("object is gone").GRPCStatus().Err()
+	header := http.Header{"Accept": []string{"application/json;q=0.5, application/problem+json"}}
+
+	resp := serveError(t, cerr.CustomErrorHandler, err, header)
+	assert.Equal(http.StatusGone, resp.StatusCode)
+	assert.Equal(cerr.ProblemJSONContentType, resp.Header.Get("Content-Type"))
+
+	body := readBody(t, resp)
+	assert.Equal(map[string]any{
+		"type":      "urn:aserto:error:E10001",
+		"title":     "not found",
+		"status":    float64(http.StatusGone),
+		"detail":    "object is gone",
+		"code":      "E10001",
+		"object_id": "1234",
+	}, body)
+}
+
+func TestErrorHandlerProblemDetailsOption(t *testing.T) {
+	assert := require.New(t)
+
+	handler := cerr.NewErrorHandler(cerr.WithProblemDetails(), cerr.WithProblemTypeBaseURI("https://example.com/errors/"))
+	err := ErrAlreadyExists.Str("status", "shadowed").GRPCStatus().Err()
+
+	resp := serveError(t, handler, err, nil)
+	assert.Equal(http.StatusConflict, resp.StatusCode)
+	assert.Equal(cerr.ProblemJSONContentType, resp.Header.Get("Content-Type"))
+
+	body := readBody(t, resp)
+	assert.Equal("https://example.com/errors/E10002", body["type"])
+	assert.Equal("already exists", body["title"])
+	assert.InDelta(float64(http.StatusConflict), body["status"], 0)
+	assert.NotContains(body, "detail")
+}
+
+func TestErrorHandlerProblemDetailsPlainErrors(t *testing.T) {
+	assert := require.New(t)
+
+	handler := cerr.NewErrorHandler(cerr.WithProblemDetails())
+
+	resp := serveError(t, handler, status.Error(codes.Unauthenticated, "token expired"), nil)
+	assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal("token expired", resp.Header.Get("WWW-Authenticate"))
+	assert.Equal(map[string]any{
+		"type":   "about:blank",
+		"title":  "Unauthorized",
+		"status": float64(http.StatusUnauthorized),
+		"detail": "token expired",
+	}, readBody(t, resp))
+
+	resp = serveError(t, handler, &runtime.HTTPStatusError{HTTPStatus: http.StatusMethodNotAllowed, Err: status.Error(codes.Unimplemented, "")}, nil)
+	assert.Equal(http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(map[string]any{
+		"type":   "about:blank",
+		"title":  "Method Not Allowed",
+		"status": float64(http.StatusMethodNotAllowed),
+	}, readBody(t, resp))
+}
+
+func TestErrorHandlerDefaultBodyWithoutProblemDetails(t *testing.T) {
+	assert := require.New(t)
+
+	resp := serveError(t, cerr.NewErrorHandler(), ErrNotFound.GRPCStatus().Err(), http.Header{"Accept": []string{"application/json"}})
+	assert.Equal(http.StatusNotFound, resp.StatusCode)
+	assert.Equal("application/json", resp.Header.Get("Content-Type"))
+}
+
+func TestErrorHandlerProblemDetailsServerMetadata(t *testing.T) {
+	assert := require.New(t)
+
+	for _, handler := range []runtime.ErrorHandlerFunc{cerr.NewErrorHandler(), cerr.NewErrorHandler(cerr.WithProblemDetails())} {
+		mux := runtime.NewServeMux(runtime.WithErrorHandler(handler))
+		assert.NoError(mux.HandlePath(http.MethodGet, "/api/v1/objects", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{
+				HeaderMD:  metadata.Pairs("x-request-id", "abc"),
+				TrailerMD: metadata.Pairs("x-checksum", "123"),
+			})
+
+			_, marshaler := runtime.MarshalerForRequest(mux, r)
+			runtime.HTTPError(ctx, mux, marshaler, w, r, ErrNotFound.GRPCStatus().Err())
+		}))
+
+		server := httptest.NewServer(mux)
+		t.Cleanup(server.Close)
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/api/v1/objects", http.NoBody)
+		assert.NoError(err)
+		req.Header.Set("TE", "trailers")
+
+		resp, err := server.Client().Do(req)
+		assert.NoError(err)
+		t.Cleanup(func() { resp.Body.Close() })
+
+		assert.Equal(http.StatusNotFound, resp.StatusCode)
+		assert.Equal("abc", resp.Header.Get("Grpc-Metadata-X-Request-Id"))
+
+		_, err = io.ReadAll(resp.Body)
+		assert.NoError(err)
+		assert.Equal("123", resp.Trailer.Get("Grpc-Trailer-X-Checksum"))
+	}
+}
+
+func TestCustomErrorHandlerProblemDetailsQuality(t *testing.T) {
+	assert := require.New(t)
+
+	header := http.Header{"Accept": []string{"application/json, application/problem+json;q=0"}}
+
+	resp := serveError(t, cerr.CustomErrorHandler, ErrNotFound.GRPCStatus().Err(), header)
+	assert.Equal("application/json", resp.Header.Get("Content-Type"))
+
+	header = http.Header{"Accept": []string{"application/json;q=0.5, application/problem+json;q=0.8"}}
+
+	resp = serveError(t, cerr.CustomErrorHandler, ErrNotFound.GRPCStatus().Err(), header)
+	assert.Equal(cerr.ProblemJSONContentType, resp.Header.Get("Content-Type"))
+}
@@ -6,7 +6,7 @@ import (
 	"io"
 	"maps"
 	"net/http"
-	"strconv"
+	"slices"
 	"strings"
 	"time"
 
@@ -24,8 +24,6 @@ const (
 
 var (
 	ErrUnknown = NewAsertoError("E00000", codes.Internal, http.StatusInternalServerError, "an unknown error has occurred")
-
-	asertoErrors = make(map[string]*AsertoError) //nolint:gochecknoglobals
 )
 
 // AsertoError represents a well known error
@@ -35,19 +33,58 @@ type AsertoError struct {
 	StatusCode codes.Code
 	Message    string
 	HTTPCode   int
-	data       map[string]string
+	data       *attributes
 	errs       []error
-}
+	violations []FieldViolation
+	stack      []uintptr
+	retryAfter time.Duration
+	locale     string
+	localized  *errdetails.LocalizedMessage
+	logLevel   zerolog.Level
+
+	contextFields []contextField
+
+	hasLogLevel  bool
+	retryable    bool
+	sentinel     bool
+	unregistered bool
+}
+
+// NewAsertoError creates a new AsertoError and adds it to the default registry.
+// For backwards compatibility an error previously registered with the same code is replaced and the message is
+// not validated, use DefaultRegistry().Register to detect duplicate codes and malformed message templates.
+func NewAsertoError(code string, statusCode codes.Code, httpCode int, msg string, opts ...ErrorOption) *AsertoError {
+	asertoError := newAsertoError(code, statusCode, httpCode, msg)
+	asertoError.sentinel = true
+
+	for _, opt := range opts {
+		opt(asertoError)
+	}
 
-func NewAsertoError(code string, statusCode codes.Code, httpCode int, msg string) *AsertoError {
-	asertoError := &AsertoError{code, statusCode, msg, httpCode, map[string]string{}, nil}
-	asertoErrors[code] = asertoError
+	defaultRegistry.set(asertoError)
 
 	return asertoError
 }
 
+func newAsertoError(code string, statusCode codes.Code, httpCode int, msg string) *AsertoError {
+	return &AsertoError{
+		Code:       code,
+		StatusCode: statusCode,
+		Message:    msg,
+		HTTPCode:   httpCode,
+	}
+}
+
+// Data returns the attributes of the error formatted as strings.
+// Sensitive attributes are not redacted.
 func (e *AsertoError) Data() map[string]string {
-	return e.Copy().data
+	result := make(map[string]string, e.data.len())
+
+	for k, v := range e.data.all() {
+		result[k] = formatValue(v)
+	}
+
+	return result
 }
 
 // SameAs returns true if the provided error is an AsertoError
@@ -61,70 +98,218 @@ func (e *AsertoError) SameAs(err error) bool {
 	return aErr.Code == e.Code
 }
 
+// Is returns true if target is an AsertoError, or a gRPC status carrying one, with the same code as e.
+// It makes errors.Is(err, ErrNotFound) match the copies produced by the builder methods.
+// gRPC statuses carrying a registered code found among the inner errors of e are matched as well.
+//
+// errors.Is only calls the Is method of the errors in the chain of err, so a gRPC status that is not wrapped
+// in an AsertoError or a ContextError never matches: errors.Is(st.Err(), ErrNotFound) returns false.
+// Use Equals to compare such errors, or the client interceptors to convert them into AsertoErrors.
+func (e *AsertoError) Is(target error) bool {
+	if e == nil {
+		return false
+	}
+
+	code, ok := errorCode(target)
+	if !ok {
+		return false
+	}
+
+	if e.Code == code {
+		return true
+	}
+
+	for _, err := range e.errs {
+		if statusCode, ok := wrappedStatusCode(err); ok && statusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Copy returns a copy of the error. When stack traces are enabled, copies of registered errors
+// record the stack of their caller.
 func (e *AsertoError) Copy() *AsertoError {
-	dataCopy := make(map[string]string, len(e.data))
+	c := e.copy()
+
+	if e.sentinel && c.stack == nil && stackTracesEnabled.Load() {
+		c.stack = callers()
+	}
 
-	maps.Copy(dataCopy, e.data)
+	return c
+}
 
+// copy returns a shallow copy of the error. The attributes, inner errors and field violations are
+// never modified in place, so they are shared with the copy.
+func (e *AsertoError) copy() *AsertoError {
 	return &AsertoError{
 		Code:       e.Code,
 		StatusCode: e.StatusCode,
 		Message:    e.Message,
-		data:       dataCopy,
+		data:       e.data,
 		errs:       e.errs,
+		violations: e.violations,
+		stack:      e.stack,
 		HTTPCode:   e.HTTPCode,
+		retryAfter: e.retryAfter,
+		locale:     e.locale,
+		localized:  e.localized,
+		logLevel:   e.logLevel,
+
+		contextFields: e.contextFields,
+
+		hasLogLevel:  e.hasLogLevel,
+		retryable:    e.retryable,
+		unregistered: e.unregistered,
 	}
 }
 
+// Unregistered returns true if the error was rebuilt from a gRPC status carrying
+// a code that is not registered in this process.
+func (e *AsertoError) Unregistered() bool {
+	return e.unregistered
+}
+
 func (e *AsertoError) Error() string {
-	errsMessage := ""
+	return e.errorString(logSink)
+}
 
-	if len(e.errs) > 0 {
-		errsMessage = e.errs[0].Error()
+// errorString returns the error string of the error with its message, msg attribute and inner AsertoErrors
+// rendered for sink s.
+func (e *AsertoError) errorString(s sink) string {
+	var sb strings.Builder
+
+	sb.WriteString(e.Code)
+	sb.WriteByte(' ')
+	sb.WriteString(e.message(s))
+
+	errsMessage := joinErrors(s, e.errs)
+
+	// a msg attribute omitted by the redaction policy is rendered as if it was not set.
+	msg, hasMsg := e.data.get(MessageKey)
+	if hasMsg {
+		msg, hasMsg = redactValue(s, MessageKey, msg)
+	}
 
-		for _, err := range e.errs[1:] {
-			errsMessage = errsMessage + colon + err.Error()
+	if hasMsg {
+		formatted := formatValue(msg)
+
+		if formatted != "" || errsMessage != "" {
+			sb.WriteString(colon)
+			sb.WriteString(formatted)
+		}
+
+		if errsMessage != "" {
+			sb.WriteString(colon)
+			sb.WriteString(errsMessage)
 		}
+
+		return sb.String()
+	}
+
+	if errsMessage != "" {
+		sb.WriteString(colon)
+		sb.WriteString(errsMessage)
 	}
 
-	innerMessage := errsMessage
+	return sb.String()
+}
 
-	if len(e.data) > 0 {
-		for k, v := range e.data {
-			if k == "msg" {
-				if innerMessage != "" {
-					innerMessage = colon + innerMessage
-				}
+// joinErrors joins the messages of errs rendered for sink s.
+func joinErrors(s sink, errs []error) string {
+	var sb strings.Builder
 
-				innerMessage = v + innerMessage
-			}
+	for i, err := range errs {
+		if i > 0 {
+			sb.WriteString(colon)
 		}
+
+		sb.WriteString(errorMessage(s, err))
+	}
+
+	return sb.String()
+}
+
+// errorMessage returns the error string of err with the AsertoErrors of its tree rendered for sink s.
+// Wrappers format the error strings of the errors they wrap themselves, so the log rendering of the
+// outermost AsertoErrors they wrap is replaced with the one of s.
+func errorMessage(s sink, err error) string {
+	if aerr, ok := err.(*AsertoError); ok { //nolint:errorlint // wrapped errors are handled below.
+		return aerr.errorString(s)
 	}
 
-	if innerMessage == "" {
-		return fmt.Sprintf("%s %s", e.Code, e.Message)
+	msg := err.Error()
+	if s == logSink {
+		return msg
 	}
 
-	return fmt.Sprintf("%s %s: %s", e.Code, e.Message, innerMessage)
+	walk(err, 0, func(inner error, _ int) bool {
+		aerr, ok := inner.(*AsertoError) //nolint:errorlint // the tree is traversed by walk.
+		if ok {
+			msg = strings.Replace(msg, aerr.Error(), aerr.errorString(s), 1)
+		}
+
+		return !ok
+	})
+
+	return msg
 }
 
+// Fields returns the attributes of the error merged with the ones of all the AsertoErrors found
+// in the tree of its inner errors. Attributes of outer errors take precedence over inner ones.
+// The fields extracted from contexts by the extractors registered with RegisterContextExtractor are added
+// unless the errors have attributes with the same names.
+// Sensitive attributes are redacted according to the Log redaction policy.
 func (e *AsertoError) Fields() map[string]any {
-	result := make(map[string]any, len(e.data))
+	result := e.fields()
 
-	for _, err := range e.errs {
-		var aerr *AsertoError
-		if ok := errors.As(err, &aerr); ok {
-			maps.Copy(result, aerr.Fields())
+	for _, field := range e.extractedFields() {
+		if _, ok := result[field.key]; ok {
+			continue
+		}
+
+		if redacted, ok := redactValue(logSink, field.key, field.value); ok {
+			result[field.key] = redacted
 		}
 	}
 
-	for k, v := range e.data {
-		result[k] = v
+	return result
+}
+
+// fields returns the attributes of the error and its inner errors, without the fields extracted from contexts.
+func (e *AsertoError) fields() map[string]any {
+	result := make(map[string]any, e.data.len())
+
+	for _, err := range e.errs {
+		collectFields(err, result)
+	}
+
+	for k, v := range e.data.all() {
+		if redacted, ok := redactValue(logSink, k, v); ok {
+			result[k] = redacted
+		} else {
+			delete(result, k)
+		}
 	}
 
 	return result
 }
 
+// collectFields adds the fields of the AsertoErrors found in the tree of err to result.
+func collectFields(err error, result map[string]any) {
+	switch x := err.(type) { //nolint:errorlint // the tree is traversed explicitly.
+	case *AsertoError:
+		maps.Copy(result, x.fields())
+	case interface{ Unwrap() []error }:
+		for _, inner := range x.Unwrap() {
+			collectFields(inner, result)
+		}
+	case interface{ Unwrap() error }:
+		collectFields(x.Unwrap(), result)
+	}
+}
+
 // Err associates err with the AsertoError.
 func (e *AsertoError) Err(err error) *AsertoError {
 	if err == nil {
@@ -132,86 +317,58 @@ func (e *AsertoError) Err(err error) *AsertoError {
 	}
 
 	c := e.Copy()
-	c.errs = append(c.errs, err)
+	c.errs = append(slices.Clip(c.errs), err)
 
 	return c
 }
 
 func (e *AsertoError) Msg(message string) *AsertoError {
-	c := e.Copy()
-
-	if message != "" {
-		if existingMsg, ok := c.data[MessageKey]; ok {
-			c.data[MessageKey] = existingMsg + colon + message
-		} else {
-			c.data[MessageKey] = message
-		}
+	if message == "" {
+		return e.Copy()
 	}
 
-	return c
+	return e.appendMsg(message)
 }
 
 func (e *AsertoError) Msgf(message string, args ...any) *AsertoError {
-	c := e.Copy()
-
-	message = fmt.Sprintf(message, args...)
+	return e.appendMsg(fmt.Sprintf(message, args...))
+}
 
-	if existingMsg, ok := c.data[MessageKey]; ok {
-		c.data[MessageKey] = existingMsg + colon + message
-	} else {
-		c.data[MessageKey] = message
+// appendMsg returns a copy of the error whose msg attribute is suffixed with message.
+func (e *AsertoError) appendMsg(message string) *AsertoError {
+	if existingMsg, ok := e.data.get(MessageKey); ok {
+		message = formatValue(existingMsg) + colon + message
 	}
 
-	return c
+	return e.with(MessageKey, message)
 }
 
 func (e *AsertoError) Str(key, value string) *AsertoError {
-	c := e.Copy()
-	c.data[key] = value
-
-	return c
+	return e.with(key, value)
 }
 
 func (e *AsertoError) Int(key string, value int) *AsertoError {
-	c := e.Copy()
-	c.data[key] = strconv.Itoa(value)
-
-	return c
+	return e.with(key, value)
 }
 
 func (e *AsertoError) Int32(key string, value int32) *AsertoError {
-	c := e.Copy()
-	c.data[key] = strconv.FormatInt(int64(value), 10)
-
-	return c
+	return e.with(key, value)
 }
 
 func (e *AsertoError) Int64(key string, value int64) *AsertoError {
-	c := e.Copy()
-	c.data[key] = strconv.FormatInt(value, 10)
-
-	return c
+	return e.with(key, value)
 }
 
 func (e *AsertoError) Bool(key string, value bool) *AsertoError {
-	c := e.Copy()
-	c.data[key] = strconv.FormatBool(value)
-
-	return c
+	return e.with(key, value)
 }
 
 func (e *AsertoError) Duration(key string, value time.Duration) *AsertoError {
-	c := e.Copy()
-	c.data[key] = value.String()
-
-	return c
+	return e.with(key, value)
 }
 
 func (e *AsertoError) Time(key string, value time.Time) *AsertoError {
-	c := e.Copy()
-	c.data[key] = value.UTC().Format(time.RFC3339)
-
-	return c
+	return e.with(key, value)
 }
 
 func (e *AsertoError) FromReader(key string, value io.Reader) *AsertoError {
@@ -221,31 +378,47 @@ func (e *AsertoError) FromReader(key string, value io.Reader) *AsertoError {
 		return e.Err(err)
 	}
 
-	c := e.Copy()
-	c.data[key] = buf.String()
-
-	return c
+	return e.with(key, buf.String())
 }
 
+// Interface returns a copy of the error with an additional attribute. Values of the kinds covered by the typed getters
+// are kept as is, any other value is formatted with %+v right away, so that later changes to it are not observed.
 func (e *AsertoError) Interface(key string, value any) *AsertoError {
+	switch value.(type) {
+	case string, int, int32, int64, bool, time.Duration, time.Time:
+		return e.with(key, value)
+	default:
+		return e.with(key, fmt.Sprintf("%+v", value))
+	}
+}
+
+// with returns a copy of the error with an additional attribute.
+func (e *AsertoError) with(key string, value any) *AsertoError {
 	c := e.Copy()
-	c.data[key] = fmt.Sprintf("%+v", value)
+	c.data = c.data.with(key, value)
 
 	return c
 }
 
-func (e *AsertoError) Unwrap() error {
+// Unwrap returns the inner errors associated with the AsertoError using Err, in the order they were added.
+func (e *AsertoError) Unwrap() []error {
 	if e == nil {
 		return nil
 	}
 
-	if len(e.errs) > 0 {
-		return e.errs[len(e.errs)-1]
+	return e.errs
+}
+
+// Errs returns a copy of the inner errors associated with the AsertoError using Err.
+func (e *AsertoError) Errs() []error {
+	if e == nil {
+		return nil
 	}
 
-	return nil
+	return slices.Clone(e.errs)
 }
 
+// Cause returns the last inner error associated with the AsertoError.
 func (e *AsertoError) Cause() error {
 	if len(e.errs) > 0 {
 		return e.errs[len(e.errs)-1]
@@ -257,15 +430,24 @@ func (e *AsertoError) Cause() error {
 func (e *AsertoError) MarshalZerologObject(event *zerolog.Event) {
 	event.Str("error", e.Error())
 	event.Fields(e.Fields())
+
+	if violations := e.FieldViolations(); len(violations) > 0 {
+		event.Array(FieldViolationsKey, fieldViolations(violations))
+	}
+
+	if len(e.stack) > 0 {
+		event.Array(zerolog.ErrorStackFieldName, stackFrames(e.stack))
+	}
 }
 
+// GRPCStatus encodes the error as a gRPC status whose message is rendered from the attributes of the error.
+// Besides the code and data of the error, the status details carry the HTTP status code under HTTPStatusErrorMetadata,
+// the inner errors, the field violations and the retry delay, so that FromGRPCStatus can rebuild an identical AsertoError on the receiving side.
 func (e *AsertoError) GRPCStatus() *status.Status {
-	errResult := status.New(e.StatusCode, e.Message)
+	enc := &statusEncoder{}
+	enc.encode(e)
 
-	errResult, err := errResult.WithDetails(&errdetails.ErrorInfo{
-		Metadata: e.Data(),
-		Domain:   e.Code,
-	})
+	errResult, err := status.New(e.StatusCode, e.message(grpcSink)).WithDetails(enc.details...)
 	if err != nil {
 		return status.New(codes.Internal, "internal failure setting up error details, please contact the administrator")
 	}
@@ -291,93 +473,38 @@ func (e *AsertoError) Ctx(ctx context.Context) error {
 	return WithContext(e, ctx)
 }
 
-// FromGRPCStatus returns an Aserto error based on a given grpcStatus. The details that are not of type errdetails.ErrorInfo are dropped.
-// and if there are details from multiple errors, the aserto error will be constructed based on the first one.
+// FromGRPCStatus returns an Aserto error based on a given grpcStatus, looking codes up in the default registry.
+// See Registry.FromGRPCStatus.
 func FromGRPCStatus(grpcStatus status.Status) *AsertoError {
-	var result *AsertoError
-
-	if len(grpcStatus.Details()) == 0 {
-		return ErrUnknown.Msg(grpcStatus.Message())
-	}
-
-	for _, detail := range grpcStatus.Details() {
-		if t, ok := detail.(*errdetails.ErrorInfo); ok {
-			result = asertoErrors[t.GetDomain()]
-			if result == nil {
-				return nil
-			}
-
-			result.data = t.GetMetadata()
-		}
-
-		if result != nil {
-			break
-		}
-	}
-
-	return result
+	return defaultRegistry.FromGRPCStatus(grpcStatus)
 }
 
 // Logger retrieves the most inner logger associated with an error.
+// The whole tree of the error is searched, and the logger of the deepest ContextError wins.
 func Logger(err error) *zerolog.Logger {
-	var (
-		logger *zerolog.Logger
-		ce     *ContextError
-	)
+	var logger *zerolog.Logger
 
-	if err == nil {
-		return logger
-	}
+	loggerDepth := -1
 
-	for {
-		if errors.As(err, &ce) {
+	walk(err, 0, func(err error, depth int) bool {
+		if ce, ok := err.(*ContextError); ok && depth >= loggerDepth { //nolint:errorlint // the tree is traversed by walk.
 			if ctxLogger := extractLogger(ce.Ctx); ctxLogger != nil {
 				logger = ctxLogger
+				loggerDepth = depth
 			}
 		}
 
-		err = errors.Unwrap(err)
-		if err == nil {
-			break
-		}
-	}
+		return true
+	})
 
 	return logger
 }
 
+// UnwrapAsertoError returns the first AsertoError found in the tree of err.
+// If there is none, it tries to construct one from the first gRPC status found in the tree,
+// looking codes up in the default registry.
 func UnwrapAsertoError(err error) *AsertoError {
-	if err == nil {
-		return nil
-	}
-
-	initialError := errors.Cause(err)
-	if initialError == nil {
-		initialError = err
-	}
-
-	// try to process Aserto error.
-	for {
-		var aErr *AsertoError
-		if ok := errors.As(err, &aErr); ok {
-			return aErr
-		}
-
-		err = errors.Unwrap(err)
-		if err == nil {
-			break
-		}
-	}
-
-	// If it's not an Aserto error, try to construct one from grpc status.
-	grpcStatus, ok := status.FromError(initialError)
-	if ok {
-		aErr := FromGRPCStatus(*grpcStatus)
-		if aErr != nil {
-			return aErr
-		}
-	}
-
-	return nil
+	return defaultRegistry.UnwrapAsertoError(err)
 }
 
 // Equals returns true if the given errors are Aserto errors with the same code or both of them are nil.
@@ -396,8 +523,28 @@ func Equals(err1, err2 error) bool {
 	return asertoErr1.Code == asertoErr2.Code
 }
 
+// CodeToAsertoError returns the error registered with the given code in the default registry, or nil.
 func CodeToAsertoError(code string) *AsertoError {
-	return asertoErrors[code]
+	asertoError, _ := defaultRegistry.Lookup(code)
+
+	return asertoError
+}
+
+// walk calls fn for err and every error in its tree in depth-first order, along with their depth.
+// The errors wrapped by an error are skipped if fn returns false for it.
+func walk(err error, depth int, fn func(err error, depth int) bool) {
+	if err == nil || !fn(err, depth) {
+		return
+	}
+
+	switch x := err.(type) { //nolint:errorlint // the tree is traversed explicitly.
+	case interface{ Unwrap() []error }:
+		for _, inner := range x.Unwrap() {
+			walk(inner, depth+1, fn)
+		}
+	case interface{ Unwrap() error }:
+		walk(x.Unwrap(), depth+1, fn)
+	}
 }
 
 /**
@@ -2,6 +2,7 @@ package errors_test
 
 import (
 	"context"
+	stderrors "errors"
 	"net/http"
 	"os"
 	"testing"
@@ -21,8 +22,8 @@ var (
 	ErrAlreadyExists = newErr("E10002", codes.AlreadyExists, http.StatusConflict, "already exists")
 )
 
-func newErr(code string, statusCode codes.Code, httpCode int, msg string) *cerr.AsertoError {
-	return cerr.NewAsertoError(code, statusCode, httpCode, msg)
+func newErr(code string, statusCode codes.Code, httpCode int, msg string, opts ...cerr.ErrorOption) *cerr.AsertoError {
+	return cerr.NewAsertoError(code, statusCode, httpCode, msg, opts...)
 }
 
 func TestDoubleCerr(t *testing.T) {
@@ -329,3 +330,145 @@ func TestLoggerWithWrappedMultipleAsertoErrorsWithMultipleContextsOuter(t *testi
 	assert.NotEqual(logger, ctx1Logger)
 	assert.Equal(logger, ctx2Logger)
 }
+
+func TestErrorsIs(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	assert.ErrorIs(ErrNotFound, ErrNotFound)
+	assert.ErrorIs(ErrNotFound.Msg("bla").Str("key", "value"), ErrNotFound)
+	assert.ErrorIs(ErrNotFound.Msg("bla"), ErrNotFound.Msg("ala"))
+	assert.ErrorIs(errors.Wrap(ErrNotFound.Msg("bla").Ctx(ctx), "wrapped"), ErrNotFound)
+	assert.ErrorIs(cerr.WrapContext(ErrNotFound.Msg("bla"), ctx, "wrapped"), ErrNotFound)
+	assert.ErrorIs(stderrors.Join(errors.New("boom"), ErrNotFound.Msg("bla")), ErrNotFound)
+	assert.ErrorIs(ErrAlreadyExists.Err(errors.New("boom")).Err(ErrNotFound.Msg("bla")), ErrNotFound)
+	assert.ErrorIs(ErrAlreadyExists.Err(ErrNotFound.Msg("bla")).Err(errors.New("boom")), ErrAlreadyExists)
+
+	assert.NotErrorIs(ErrNotFound.Msg("bla"), ErrAlreadyExists)
+	assert.NotErrorIs(errors.New("boom"), ErrNotFound)
+	assert.NotErrorIs(nil, ErrNotFound)
+}
+
+func TestErrorsIsGRPCStatus(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	statusErr := ErrNotFound.Msg("bla").GRPCStatus().Err()
+
+	assert.ErrorIs(ErrNotFound.Msg("ala"), statusErr)
+	assert.ErrorIs(cerr.WithContext(statusErr, ctx), ErrNotFound)
+	assert.ErrorIs(errors.Wrap(cerr.WrapContext(statusErr, ctx, "wrapped"), "wrapped again"), ErrNotFound)
+	assert.ErrorIs(cerr.ErrUnknown.Err(errors.Wrap(statusErr, "wrapped")), ErrNotFound)
+
+	assert.NotErrorIs(cerr.WithContext(statusErr, ctx), ErrAlreadyExists)
+	assert.NotErrorIs(cerr.WithContext(status.Error(codes.NotFound, "not found"), ctx), ErrNotFound)
+	assert.NotErrorIs(ErrAlreadyExists, statusErr)
+}
+
+func TestErrorsIsBareGRPCStatus(t *testing.T) {
+	assert := require.New(t)
+
+	statusErr := ErrNotFound.Msg("bla").GRPCStatus().Err()
+
+	// errors.Is only asks the errors of the chain, statuses that are not wrapped in an AsertoError
+	// or a ContextError cannot match.
+	assert.NotErrorIs(statusErr, ErrNotFound)
+	assert.NotErrorIs(errors.Wrap(statusErr, "wrapped"), ErrNotFound)
+	assert.NotErrorIs(stderrors.Join(statusErr), ErrNotFound)
+
+	assert.True(cerr.Equals(statusErr, ErrNotFound))
+	assert.True(cerr.Equals(errors.Wrap(statusErr, "wrapped"), ErrNotFound))
+}
+
+func TestErrorsIsUnregisteredGRPCStatus(t *testing.T) {
+	assert := require.New(t)
+
+	foreign := cerr.NewRegistry().MustRegister("E99010", codes.NotFound, http.StatusNotFound, "not found")
+	statusErr := foreign.GRPCStatus().Err()
+
+	assert.NotErrorIs(cerr.WithContext(statusErr, context.Background()), foreign)
+	assert.NotErrorIs(cerr.ErrUnknown.Err(statusErr), foreign)
+}
+
+func TestUnwrapMultipleErrors(t *testing.T) {
+	assert := require.New(t)
+
+	boom := errors.New("boom")
+	err := cerr.ErrUnknown.Err(ErrNotFound.Str("first", "1")).Err(boom)
+
+	assert.Equal([]error{err.Errs()[0], boom}, err.Unwrap())
+	assert.Equal(boom, err.Cause())
+
+	// the AsertoError added first is reachable even though it is not the cause.
+	var aerr *cerr.AsertoError
+	assert.True(stderrors.As(err, &aerr))
+	assert.True(cerr.ErrUnknown.SameAs(aerr))
+	assert.ErrorIs(err, ErrNotFound)
+	assert.ErrorIs(err, boom)
+
+	var notFound *cerr.AsertoError
+	assert.True(stderrors.As(err.Errs()[0], &notFound))
+	assert.True(ErrNotFound.SameAs(notFound))
+
+	errs := err.Errs()
+	errs[0] = nil
+	assert.NotNil(err.Errs()[0], "Errs returns a copy")
+}
+
+func TestErrDoesNotShareInnerErrors(t *testing.T) {
+	assert := require.New(t)
+
+	base := ErrNotFound.Err(errors.New("one")).Err(errors.New("two")).Err(errors.New("three"))
+	first := base.Err(errors.New("first"))
+	second := base.Err(errors.New("second"))
+
+	assert.Equal("E10001 not found: one: two: three: first", first.Error())
+	assert.Equal("E10001 not found: one: two: three: second", second.Error())
+}
+
+func TestFieldsTraverseTree(t *testing.T) {
+	assert := require.New(t)
+
+	err := cerr.ErrUnknown.
+		Err(ErrNotFound.Str("first", "1").Str("shared", "first")).
+		Err(stderrors.Join(
+			errors.Wrap(ErrAlreadyExists.Str("second", "2").Str("shared", "second"), "wrapped"),
+			cerr.WithContext(cerr.ErrUnknown.Str("third", "3"), context.Background()),
+		)).
+		Str("own", "0")
+
+	fields := err.Fields()
+	assert.Equal("0", fields["own"])
+	assert.Equal("1", fields["first"])
+	assert.Equal("2", fields["second"])
+	assert.Equal("3", fields["third"])
+	assert.Equal("second", fields["shared"])
+
+	fields = ErrAlreadyExists.Err(ErrNotFound.Str("shared", "inner")).Str("shared", "outer").Fields()
+	assert.Equal("outer", fields["shared"])
+}
+
+func TestUnwrapAsertoErrorTraversesTree(t *testing.T) {
+	assert := require.New(t)
+
+	err := stderrors.Join(errors.New("boom"), errors.Wrap(ErrNotFound.Msg("bla"), "wrapped"))
+	assert.True(ErrNotFound.SameAs(cerr.UnwrapAsertoError(err)))
+
+	statusErr := stderrors.Join(errors.New("boom"), errors.Wrap(ErrAlreadyExists.GRPCStatus().Err(), "wrapped"))
+	assert.True(ErrAlreadyExists.SameAs(cerr.UnwrapAsertoError(statusErr)))
+}
+
+func TestLoggerTraversesTree(t *testing.T) {
+	assert := require.New(t)
+
+	initialLogger := zerolog.New(os.Stderr)
+	ctx := initialLogger.WithContext(context.Background())
+
+	err := cerr.ErrUnknown.
+		Err(ErrNotFound.Err(cerr.WithContext(errors.New("boom"), ctx))).
+		Err(errors.New("last"))
+
+	logger := cerr.Logger(stderrors.Join(errors.New("first"), err))
+	assert.NotNil(logger)
+	assert.Equal(zerolog.Ctx(ctx), logger)
+}
@@ -0,0 +1,201 @@
+package errors
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// RequestIDKey is the name of the field holding the request ID extracted by RequestIDExtractor.
+	RequestIDKey = "request_id"
+	// TenantIDKey is the name of the field holding the tenant ID extracted by TenantIDExtractor.
+	TenantIDKey = "tenant_id"
+	// SubjectKey is the name of the field holding the authenticated subject extracted by SubjectExtractor.
+	SubjectKey = "subject"
+
+	// RequestIDMetadataKey is the incoming gRPC metadata key read by RequestIDExtractor.
+	RequestIDMetadataKey = "x-request-id"
+	// TenantIDMetadataKey is the incoming gRPC metadata key read by TenantIDExtractor.
+	TenantIDMetadataKey = "aserto-tenant-id"
+)
+
+// ContextExtractor returns fields extracted from a context, or nil if the context holds none.
+type ContextExtractor func(ctx context.Context) map[string]any
+
+// ContextExtractorOption configures a ContextExtractor registered with RegisterContextExtractor.
+type ContextExtractorOption func(*contextExtractor)
+
+// WithExtractedMetadata sends the fields extracted by the registered ContextExtractor in the ErrorInfo metadata
+// of the gRPC status of the errors, so that they are received as attributes.
+func WithExtractedMetadata() ContextExtractorOption {
+	return func(x *contextExtractor) {
+		x.metadata = true
+	}
+}
+
+type contextExtractor struct {
+	name     string
+	extract  ContextExtractor
+	metadata bool
+}
+
+var (
+	contextExtractorsMu sync.RWMutex        //nolint:gochecknoglobals
+	contextExtractors   []*contextExtractor //nolint:gochecknoglobals
+)
+
+// RegisterContextExtractor registers an extractor applied to the contexts of the ContextErrors found in the tree
+// of errors. The extracted fields are added to Fields and MarshalZerologObject, without replacing the attributes
+// of the errors, and to the ErrorInfo metadata of their gRPC status if WithExtractedMetadata is used.
+// When the tree holds several contexts, the fields extracted from the deepest one win.
+// An extractor previously registered with the same name is replaced.
+func RegisterContextExtractor(name string, extractor ContextExtractor, opts ...ContextExtractorOption) {
+	x := &contextExtractor{name: name, extract: extractor}
+	for _, opt := range opts {
+		opt(x)
+	}
+
+	contextExtractorsMu.Lock()
+	defer contextExtractorsMu.Unlock()
+
+	if i := slices.IndexFunc(contextExtractors, func(c *contextExtractor) bool { return c.name == name }); i >= 0 {
+		contextExtractors[i] = x
+
+		return
+	}
+
+	contextExtractors = append(contextExtractors, x)
+}
+
+// UnregisterContextExtractor removes the extractor registered with the given name.
+func UnregisterContextExtractor(name string) {
+	contextExtractorsMu.Lock()
+	defer contextExtractorsMu.Unlock()
+
+	contextExtractors = slices.DeleteFunc(contextExtractors, func(c *contextExtractor) bool { return c.name == name })
+}
+
+// MetadataExtractor returns a ContextExtractor extracting the first value of the given key of the incoming
+// gRPC metadata as the given field.
+func MetadataExtractor(field, key string) ContextExtractor {
+	return func(ctx context.Context) map[string]any {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return nil
+		}
+
+		values := md.Get(key)
+		if len(values) == 0 || values[0] == "" {
+			return nil
+		}
+
+		return map[string]any{field: values[0]}
+	}
+}
+
+// ValueExtractor returns a ContextExtractor extracting the value associated with key in the context as the given field.
+func ValueExtractor(field string, key any) ContextExtractor {
+	return func(ctx context.Context) map[string]any {
+		value := ctx.Value(key)
+		if value == nil {
+			return nil
+		}
+
+		return map[string]any{field: value}
+	}
+}
+
+// RequestIDExtractor returns a ContextExtractor extracting the request ID found in the incoming gRPC metadata
+// under RequestIDMetadataKey as RequestIDKey.
+func RequestIDExtractor() ContextExtractor {
+	return MetadataExtractor(RequestIDKey, RequestIDMetadataKey)
+}
+
+// TenantIDExtractor returns a ContextExtractor extracting the tenant ID found in the incoming gRPC metadata
+// under TenantIDMetadataKey as TenantIDKey.
+func TenantIDExtractor() ContextExtractor {
+	return MetadataExtractor(TenantIDKey, TenantIDMetadataKey)
+}
+
+// SubjectExtractor returns a ContextExtractor extracting the authenticated subject associated with key
+// in the context as SubjectKey.
+func SubjectExtractor(key any) ContextExtractor {
+	return ValueExtractor(SubjectKey, key)
+}
+
+// contextField is a field extracted from a context.
+type contextField struct {
+	key      string
+	value    any
+	metadata bool
+}
+
+// extractContextFields applies the registered extractors to the contexts of the ContextErrors found in the tree
+// of err, and returns the extracted fields sorted by key. The fields of the deepest context win.
+func extractContextFields(err error) []contextField {
+	contextExtractorsMu.RLock()
+	extractors := slices.Clone(contextExtractors)
+	contextExtractorsMu.RUnlock()
+
+	if len(extractors) == 0 {
+		return nil
+	}
+
+	fields := map[string]contextField{}
+	depths := map[string]int{}
+
+	walk(err, 0, func(err error, depth int) bool {
+		ce, ok := err.(*ContextError) //nolint:errorlint // the tree is traversed by walk.
+		if !ok || ce.Ctx == nil {
+			return true
+		}
+
+		for _, x := range extractors {
+			for key, value := range x.extract(ce.Ctx) {
+				if d, ok := depths[key]; ok && d > depth {
+					continue
+				}
+
+				fields[key] = contextField{key: key, value: value, metadata: x.metadata}
+				depths[key] = depth
+			}
+		}
+
+		return true
+	})
+
+	result := make([]contextField, 0, len(fields))
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		result = append(result, fields[key])
+	}
+
+	return result
+}
+
+// withContextFields returns a copy of the error holding the fields extracted from the contexts found in the tree
+// of err, the error the AsertoError was found in. It returns the error itself if there are none.
+func (e *AsertoError) withContextFields(err error) *AsertoError {
+	fields := extractContextFields(err)
+	if len(fields) == 0 {
+		return e
+	}
+
+	c := e.copy()
+	c.contextFields = fields
+
+	return c
+}
+
+// extractedFields returns the fields extracted from the contexts of the error, or of its tree if the error
+// was not created by withContextFields.
+func (e *AsertoError) extractedFields() []contextField {
+	if e.contextFields != nil {
+		return e.contextFields
+	}
+
+	return extractContextFields(e)
+}
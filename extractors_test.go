@@ -0,0 +1,162 @@
+package errors_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type subjectKey struct{}
+
+func registerContextExtractor(t *testing.T, name string, extractor cerr.ContextExtractor, opts ...cerr.ContextExtractorOption) {
+	t.Helper()
+
+	cerr.RegisterContextExtractor(name, extractor, opts...)
+	t.Cleanup(func() { cerr.UnregisterContextExtractor(name) })
+}
+
+func incomingContext(t *testing.T, kv ...string) context.Context {
+	t.Helper()
+
+	return metadata.NewIncomingContext(t.Context(), metadata.Pairs(kv...))
+}
+
+func TestContextExtractorFields(t *testing.T) {
+	assert := require.New(t)
+
+	registerContextExtractor(t, "request_id", cerr.RequestIDExtractor())
+	registerContextExtractor(t, "tenant_id", cerr.TenantIDExtractor())
+	registerContextExtractor(t, "subject", cerr.SubjectExtractor(subjectKey{}))
+
+	ctx := incomingContext(t, cerr.RequestIDMetadataKey, "req-1", cerr.TenantIDMetadataKey, "tenant-1")
+	ctx = context.WithValue(ctx, subjectKey{}, "user@example.com")
+
+	err := ErrNotFound.Str("object_id", "1234").Err(errors.Wrap(ErrAlreadyExists.Ctx(ctx), "insert"))
+
+	assert.Equal(map[string]any{
+		"object_id":       "1234",
+		cerr.RequestIDKey: "req-1",
+		cerr.TenantIDKey:  "tenant-1",
+		cerr.SubjectKey:   "user@example.com",
+	}, err.Fields())
+
+	assert.Empty(ErrNotFound.Str("object_id", "1234").Fields()[cerr.RequestIDKey])
+}
+
+func TestContextExtractorAttributesTakePrecedence(t *testing.T) {
+	assert := require.New(t)
+
+	registerContextExtractor(t, "request_id", cerr.RequestIDExtractor())
+
+	ctx := incomingContext(t, cerr.RequestIDMetadataKey, "req-1")
+	err := ErrNotFound.Str(cerr.RequestIDKey, "explicit").Err(ErrAlreadyExists.Ctx(ctx))
+
+	assert.Equal("explicit", err.Fields()[cerr.RequestIDKey])
+}
+
+func TestContextExtractorDeepestContextWins(t *testing.T) {
+	assert := require.New(t)
+
+	registerContextExtractor(t, "request_id", cerr.RequestIDExtractor())
+
+	outer := incomingContext(t, cerr.RequestIDMetadataKey, "outer")
+	inner := incomingContext(t, cerr.RequestIDMetadataKey, "inner")
+
+	err := ErrNotFound.Err(cerr.WithContext(ErrAlreadyExists.Err(errors.New("boom")).Ctx(inner), outer))
+
+	assert.Equal("inner", err.Fields()[cerr.RequestIDKey])
+}
+
+func TestContextExtractorSensitiveFields(t *testing.T) {
+	assert := require.New(t)
+
+	cerr.RegisterSensitiveKeys(cerr.SubjectKey)
+	t.Cleanup(func() { cerr.UnregisterSensitiveKeys(cerr.SubjectKey) })
+
+	registerContextExtractor(t, "subject", cerr.SubjectExtractor(subjectKey{}), cerr.WithExtractedMetadata())
+
+	ctx := context.WithValue(t.Context(), subjectKey{}, "user@example.com")
+	err := ErrNotFound.Err(ErrAlreadyExists.Ctx(ctx))
+
+	assert.Equal(cerr.RedactedValue, err.Fields()[cerr.SubjectKey])
+	assert.Equal(cerr.RedactedValue, errorInfoMetadata(err)[cerr.SubjectKey])
+}
+
+func TestContextExtractorMarshalZerologObject(t *testing.T) {
+	assert := require.New(t)
+
+	registerContextExtractor(t, "tenant_id", cerr.TenantIDExtractor())
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf)
+
+	err := ErrNotFound.Err(ErrAlreadyExists.Ctx(incomingContext(t, cerr.TenantIDMetadataKey, "tenant-1")))
+	logger.Error().EmbedObject(err).Msg("failed")
+
+	assert.Contains(buf.String(), `"tenant_id":"tenant-1"`)
+}
+
+func TestContextExtractorMetadata(t *testing.T) {
+	assert := require.New(t)
+
+	registerContextExtractor(t, "request_id", cerr.RequestIDExtractor())
+	registerContextExtractor(t, "tenant_id", cerr.TenantIDExtractor(), cerr.WithExtractedMetadata())
+
+	ctx := incomingContext(t, cerr.RequestIDMetadataKey, "req-1", cerr.TenantIDMetadataKey, "tenant-1")
+	err := ErrNotFound.Err(ErrAlreadyExists.Ctx(ctx))
+
+	md := errorInfoMetadata(err)
+	assert.Equal("tenant-1", md[cerr.TenantIDKey])
+	assert.NotContains(md, cerr.RequestIDKey)
+
+	received := roundTrip(t, err)
+	value, ok := received.Get(cerr.TenantIDKey)
+	assert.True(ok)
+	assert.Equal("tenant-1", value)
+}
+
+func TestContextExtractorReplaceAndUnregister(t *testing.T) {
+	assert := require.New(t)
+
+	registerContextExtractor(t, "static", func(context.Context) map[string]any { return map[string]any{"static": "first"} })
+	registerContextExtractor(t, "static", func(context.Context) map[string]any { return map[string]any{"static": "second"} })
+
+	err := ErrNotFound.Err(ErrAlreadyExists.Ctx(t.Context()))
+	assert.Equal("second", err.Fields()["static"])
+
+	cerr.UnregisterContextExtractor("static")
+	assert.NotContains(err.Fields(), "static")
+}
+
+func TestServerInterceptorsContextExtractors(t *testing.T) {
+	assert := require.New(t)
+
+	registerContextExtractor(t, "request_id", cerr.RequestIDExtractor(), cerr.WithExtractedMetadata())
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf)
+
+	conn := newTestConn(t, func(ctx context.Context) error {
+		return errors.Wrap(ErrNotFound.Ctx(logger.WithContext(ctx)), "lookup")
+	}, withServerInterceptors())
+
+	ctx := metadata.AppendToOutgoingContext(t.Context(), cerr.RequestIDMetadataKey, "req-1")
+	err := conn.Invoke(ctx, testUnaryMethod, &emptypb.Empty{}, &emptypb.Empty{}, grpc.WaitForReady(true))
+
+	received := cerr.UnwrapAsertoError(err)
+	assert.NotNil(received)
+
+	value, ok := received.Get(cerr.RequestIDKey)
+	assert.True(ok)
+	assert.Equal("req-1", value)
+
+	assert.Contains(buf.String(), `"request_id":"req-1"`)
+}
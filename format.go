@@ -0,0 +1,122 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// reportIndent indents the sections of the report printed by %+v.
+const reportIndent = "    "
+
+// Format implements fmt.Formatter.
+// %s and %v print the error message and %q prints the quoted error message.
+// %+v prints a multi-line report with the codes, attributes, field violations, retry delay and stack trace
+// of the error, followed by the reports of its inner errors.
+func (e *AsertoError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			e.writeReport(s)
+
+			return
+		}
+
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// writeReport writes the report printed by %+v. Sensitive attributes are redacted according to the Log redaction policy.
+func (e *AsertoError) writeReport(w io.Writer) {
+	const (
+		section = "\n" + reportIndent
+		item    = section + reportIndent
+	)
+
+	_, _ = io.WriteString(w, e.Error())
+
+	fmt.Fprintf(w, "%scode: %s", section, e.Code)
+	fmt.Fprintf(w, "%sgrpc code: %s", section, e.StatusCode)
+	fmt.Fprintf(w, "%shttp code: %d", section, e.HTTPCode)
+
+	attributes := map[string]any{}
+
+	for key, value := range e.data.all() {
+		if redacted, ok := redactValue(logSink, key, value); ok {
+			attributes[key] = redacted
+		}
+	}
+
+	if len(attributes) > 0 {
+		fmt.Fprintf(w, "%sattributes:", section)
+
+		for _, key := range slices.Sorted(maps.Keys(attributes)) {
+			fmt.Fprintf(w, "%s%s: %s", item, key, formatValue(attributes[key]))
+		}
+	}
+
+	if len(e.violations) > 0 {
+		fmt.Fprintf(w, "%sfield violations:", section)
+
+		for _, v := range e.violations {
+			fmt.Fprintf(w, "%s%s: %s", item, v.Field, v.Description)
+		}
+	}
+
+	switch {
+	case e.retryAfter > 0:
+		fmt.Fprintf(w, "%sretry after: %s", section, e.retryAfter)
+	case e.retryable:
+		fmt.Fprintf(w, "%sretryable: true", section)
+	}
+
+	if len(e.stack) > 0 {
+		fmt.Fprintf(w, "%sstack:", section)
+
+		frames := runtime.CallersFrames(e.stack)
+
+		for {
+			frame, more := frames.Next()
+			fmt.Fprintf(w, "%s%s%s\t%s:%d", item, frame.Function, item, frame.File, frame.Line)
+
+			if !more {
+				break
+			}
+		}
+	}
+
+	if len(e.errs) > 0 {
+		fmt.Fprintf(w, "%scauses:", section)
+
+		for _, err := range e.errs {
+			report := fmt.Sprintf("%+v", err)
+			_, _ = io.WriteString(w, item+strings.ReplaceAll(report, "\n", item))
+		}
+	}
+}
+
+// Format implements fmt.Formatter.
+// %s and %v print the error message, %+v prints the wrapped error using %+v and %q prints the quoted error message.
+func (ce *ContextError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%+v", ce.Err)
+
+			return
+		}
+
+		fallthrough
+	case 's':
+		_, _ = io.WriteString(s, ce.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", ce.Error())
+	}
+}
@@ -0,0 +1,79 @@
+package errors_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestFormatVerbs(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrNotFound.Str("object_id", "1234").Msg("lookup failed")
+
+	assert.Equal("E10001 not found: lookup failed", fmt.Sprintf("%v", err))
+	assert.Equal("E10001 not found: lookup failed", fmt.Sprintf("%s", err))
+	assert.Equal(`"E10001 not found: lookup failed"`, fmt.Sprintf("%q", err))
+
+	ctxErr := err.Ctx(t.Context())
+	assert.Equal("E10001 not found: lookup failed", fmt.Sprintf("%v", ctxErr))
+	assert.Equal("E10001 not found: lookup failed", fmt.Sprintf("%s", ctxErr))
+	assert.Equal(`"E10001 not found: lookup failed"`, fmt.Sprintf("%q", ctxErr))
+	assert.Equal(fmt.Sprintf("%+v", err), fmt.Sprintf("%+v", ctxErr))
+}
+
+func TestFormatReport(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrNotFound.
+		Str("object_type", "user").
+		Str("object_id", "1234").
+		Sensitive("token", "s3cr3t").
+		FieldViolation("user.email", "must be a valid email address").
+		Err(ErrUnavailable.Str("backend", "directory").RetryAfter(time.Second)).
+		Err(cerr.NewRegistry().MustRegister("E20060", codes.DeadlineExceeded, http.StatusGatewayTimeout, "timeout"))
+
+	expected := strings.Join([]string{
+		"E10001 not found: E10040 service unavailable: E20060 timeout",
+		"    code: E10001",
+		"    grpc code: NotFound",
+		"    http code: 404",
+		"    attributes:",
+		"        object_id: 1234",
+		"        object_type: user",
+		"        token: [REDACTED]",
+		"    field violations:",
+		"        user.email: must be a valid email address",
+		"    causes:",
+		"        E10040 service unavailable",
+		"            code: E10040",
+		"            grpc code: Unavailable",
+		"            http code: 503",
+		"            attributes:",
+		"                backend: directory",
+		"            retry after: 1s",
+		"        E20060 timeout",
+		"            code: E20060",
+		"            grpc code: DeadlineExceeded",
+		"            http code: 504",
+	}, "\n")
+
+	assert.Equal(expected, fmt.Sprintf("%+v", err))
+}
+
+func TestFormatReportWrappedCauses(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrNotFound.Err(errors.Wrap(ErrAlreadyExists, "wrapped"))
+
+	formatted := fmt.Sprintf("%+v", err)
+	assert.Contains(formatted, "    causes:\n        E10002 already exists\n            code: E10002\n")
+	assert.Contains(formatted, "\n        wrapped\n        github.com/aserto-dev/errors_test.TestFormatReportWrappedCauses\n")
+}
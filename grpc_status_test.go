@@ -0,0 +1,223 @@
+package errors_test
+
+import (
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// roundTrip sends err through the protobuf wire representation of its gRPC status.
+func roundTrip(t *testing.T, err *cerr.AsertoError) *cerr.AsertoError {
+	t.Helper()
+
+	buf, marshalErr := proto.Marshal(err.GRPCStatus().Proto())
+	require.NoError(t, marshalErr)
+
+	var pb rpcstatus.Status
+	require.NoError(t, proto.Unmarshal(buf, &pb))
+
+	return cerr.FromGRPCStatus(*status.FromProto(&pb))
+}
+
+func TestFromGRPCStatusDoesNotMutateRegisteredError(t *testing.T) {
+	assert := require.New(t)
+
+	st := ErrNotFound.Str("object_id", "1234").Msg("boom").GRPCStatus()
+
+	decoded := cerr.FromGRPCStatus(*st)
+	assert.NotSame(ErrNotFound, decoded)
+	assert.Equal("1234", decoded.Data()["object_id"])
+
+	assert.Empty(ErrNotFound.Data())
+	assert.Equal("E10001 not found", ErrNotFound.Error())
+}
+
+func TestFromGRPCStatusConcurrent(t *testing.T) {
+	assert := require.New(t)
+
+	const (
+		workers    = 16
+		iterations = 500
+	)
+
+	var wg sync.WaitGroup
+
+	failures := make(chan string, workers*iterations)
+
+	for w := range workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range iterations {
+				id := strconv.Itoa(w*iterations + i)
+
+				source := ErrNotFound
+				if i%2 == 0 {
+					source = ErrAlreadyExists
+				}
+
+				st := source.Str("id", id).Msg(id).GRPCStatus()
+
+				decoded := cerr.FromGRPCStatus(*st)
+				if decoded.Code != source.Code || decoded.Data()["id"] != id || decoded.Data()[cerr.MessageKey] != id {
+					failures <- id
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(failures)
+
+	assert.Empty(failures)
+	assert.Empty(ErrNotFound.Data())
+	assert.Empty(ErrAlreadyExists.Data())
+	assert.Equal("E10001 not found", ErrNotFound.Error())
+	assert.Equal("E10002 already exists", ErrAlreadyExists.Error())
+}
+
+func TestGRPCStatusRoundTrip(t *testing.T) {
+	assert := require.New(t)
+
+	inner := ErrAlreadyExists.Str("object_type", "user").Msg("inner").Err(errors.New("deep"))
+	sent := ErrNotFound.
+		WithGRPCStatus(codes.Aborted).
+		WithHTTPStatus(http.StatusTeapot).
+		Str("object_id", "1234").
+		Msg("outer").
+		Err(errors.New("plain")).
+		Err(inner).
+		Err(errors.Wrap(cerr.ErrUnknown.Str("wrapped_key", "wrapped_value"), "wrapped"))
+
+	received := roundTrip(t, sent)
+
+	assert.Equal(sent.Error(), received.Error())
+	assert.Equal(sent.Fields(), received.Fields())
+	assert.Equal(sent.Data(), received.Data())
+	assert.Equal(codes.Aborted, received.StatusCode)
+	assert.Equal(http.StatusTeapot, received.HTTPCode)
+
+	var receivedInner *cerr.AsertoError
+	assert.True(errors.As(received.Cause(), &receivedInner))
+	assert.Equal(cerr.ErrUnknown.Code, receivedInner.Code)
+	assert.Equal("wrapped_value", receivedInner.Data()["wrapped_key"])
+}
+
+func TestGRPCStatusRoundTripNested(t *testing.T) {
+	assert := require.New(t)
+
+	inner := ErrAlreadyExists.WithHTTPStatus(http.StatusGone).Str("object_type", "user").Err(errors.New("deep"))
+	sent := ErrNotFound.Err(inner)
+
+	received := roundTrip(t, sent)
+	assert.Equal(sent.Error(), received.Error())
+	assert.Equal(http.StatusNotFound, received.HTTPCode)
+
+	receivedInner, ok := received.Cause().(*cerr.AsertoError)
+	assert.True(ok)
+	assert.True(ErrAlreadyExists.SameAs(receivedInner))
+	assert.Equal(http.StatusGone, receivedInner.HTTPCode)
+	assert.Equal(codes.AlreadyExists, receivedInner.StatusCode)
+	assert.Equal(inner.Error(), receivedInner.Error())
+	assert.Equal(inner.Data(), receivedInner.Data())
+}
+
+func TestGRPCStatusRoundTripUnregisteredInnerCode(t *testing.T) {
+	assert := require.New(t)
+
+	registry := cerr.NewRegistry()
+	foreign := registry.MustRegister("E99001", codes.Unavailable, http.StatusServiceUnavailable, "foreign failure")
+
+	sent := ErrNotFound.Err(foreign.Msg("down"))
+
+	received := roundTrip(t, sent)
+	assert.Equal(sent.Error(), received.Error())
+
+	receivedInner, ok := received.Cause().(*cerr.AsertoError)
+	assert.True(ok)
+	assert.Equal("E99001", receivedInner.Code)
+	assert.Equal("foreign failure", receivedInner.Message)
+	assert.Equal(codes.Unavailable, receivedInner.StatusCode)
+	assert.Equal(http.StatusServiceUnavailable, receivedInner.HTTPCode)
+}
+
+func TestFromGRPCStatusUnregisteredCode(t *testing.T) {
+	assert := require.New(t)
+
+	registry := cerr.NewRegistry()
+	foreign := registry.MustRegister("E99002", codes.ResourceExhausted, http.StatusTooManyRequests, "quota exceeded")
+
+	sent := foreign.Str("tenant_id", "acme").Msg("slow down").Err(ErrNotFound.Msg("missing"))
+
+	received := roundTrip(t, sent)
+	assert.NotNil(received)
+	assert.True(received.Unregistered())
+	assert.Equal("E99002", received.Code)
+	assert.Equal(codes.ResourceExhausted, received.StatusCode)
+	assert.Equal(http.StatusTooManyRequests, received.HTTPCode)
+	assert.Equal("quota exceeded", received.Message)
+	assert.Equal(sent.Data(), received.Data())
+	assert.Equal(sent.Error(), received.Error())
+
+	assert.True(foreign.SameAs(received))
+	assert.True(cerr.Equals(sent, sent.GRPCStatus().Err()))
+	assert.Nil(cerr.CodeToAsertoError("E99002"), "synthesized errors are not registered")
+
+	unwrapped := cerr.UnwrapAsertoError(errors.Wrap(sent.GRPCStatus().Err(), "proxied"))
+	assert.NotNil(unwrapped)
+	assert.Equal("E99002", unwrapped.Code)
+
+	// forwarding the synthesized error preserves it.
+	forwarded := roundTrip(t, received)
+	assert.Equal(sent.Error(), forwarded.Error())
+	assert.Equal(http.StatusTooManyRequests, forwarded.HTTPCode)
+}
+
+func TestFromGRPCStatusUnregisteredCodeWithoutHTTPStatus(t *testing.T) {
+	assert := require.New(t)
+
+	st, err := status.New(codes.PermissionDenied, "denied").WithDetails(&errdetails.ErrorInfo{
+		Domain:   "E99003",
+		Metadata: map[string]string{"subject": "alice"},
+	})
+	assert.NoError(err)
+
+	received := cerr.FromGRPCStatus(*st)
+	assert.True(received.Unregistered())
+	assert.Equal(http.StatusForbidden, received.HTTPCode)
+	assert.Equal("E99003 denied", received.Error())
+	assert.Equal("alice", received.Data()["subject"])
+}
+
+func TestFromGRPCStatusRegisteredCode(t *testing.T) {
+	assert := require.New(t)
+
+	received := roundTrip(t, ErrNotFound.Msg("missing"))
+	assert.False(received.Unregistered())
+	assert.False(ErrNotFound.Unregistered())
+}
+
+func TestGRPCStatusRoundTripJoinedErrors(t *testing.T) {
+	assert := require.New(t)
+
+	sent := cerr.ErrUnknown.Err(stderrors.Join(ErrNotFound.Str("first", "1"), ErrAlreadyExists.Str("second", "2")))
+
+	received := roundTrip(t, sent)
+	assert.Equal(sent.Error(), received.Error())
+	assert.Equal(sent.Fields(), received.Fields())
+	assert.ErrorIs(received, ErrNotFound)
+	assert.ErrorIs(received, ErrAlreadyExists)
+}
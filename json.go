@@ -0,0 +1,214 @@
+package errors
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc/codes"
+)
+
+// JSONVersion is the version of the JSON wire format of AsertoError.
+//
+// Version 1 encodes an error as an object with the following members:
+//
+//	version           the version of the format, only set on the outermost error
+//	code              the code of the error
+//	message           the message, or message template, of the error
+//	error             the error string with sensitive values redacted, informational only
+//	grpc_code         the name of the gRPC status code, e.g. "NOT_FOUND"
+//	http_code         the HTTP status code
+//	attributes        the attributes of the error formatted as strings
+//	sensitive         the keys of the sensitive attributes that were not redacted
+//	field_violations  the field violations, as objects with field and description members
+//	retryable         true if the error is safe to retry
+//	retry_after       the retry delay, e.g. "1.5s"
+//	causes            the inner errors, errors that are not AsertoErrors only have message and causes members
+//
+// Members are omitted when empty. New members can be added without changing the version.
+const JSONVersion = 1
+
+// ErrUnsupportedJSONVersion is returned when unmarshalling an error encoded with a newer version of the JSON wire format.
+var ErrUnsupportedJSONVersion = errors.New("unsupported JSON version")
+
+type jsonError struct {
+	Version         int                  `json:"version,omitempty"`
+	Code            string               `json:"code,omitempty"`
+	Message         string               `json:"message"`
+	Error           string               `json:"error,omitempty"`
+	GRPCCode        string               `json:"grpc_code,omitempty"`
+	HTTPCode        int                  `json:"http_code,omitempty"`
+	Attributes      map[string]string    `json:"attributes,omitempty"`
+	Sensitive       []string             `json:"sensitive,omitempty"`
+	FieldViolations []jsonFieldViolation `json:"field_violations,omitempty"`
+	Retryable       bool                 `json:"retryable,omitempty"`
+	RetryAfter      string               `json:"retry_after,omitempty"`
+	Causes          []*jsonError         `json:"causes,omitempty"`
+}
+
+type jsonFieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// MarshalJSON encodes the error and its inner errors using the JSON wire format described by JSONVersion.
+// Sensitive attributes are redacted according to the GRPC redaction policy.
+func (e *AsertoError) MarshalJSON() ([]byte, error) {
+	result := newJSONError(e)
+	result.Version = JSONVersion
+
+	return json.Marshal(result)
+}
+
+// UnmarshalJSON decodes an error encoded using the JSON wire format described by JSONVersion.
+// As with FromGRPCStatus, errors whose code is registered keep their registered message, and
+// errors whose code is not registered are rebuilt from the content of the JSON and reported as Unregistered.
+// Codes are looked up in the default registry, use Registry.FromJSON to decode errors of another registry.
+func (e *AsertoError) UnmarshalJSON(data []byte) error {
+	result, err := defaultRegistry.FromJSON(data)
+	if err != nil {
+		return err
+	}
+
+	*e = *result
+
+	return nil
+}
+
+// FromJSON decodes an error encoded using the JSON wire format described by JSONVersion,
+// like AsertoError.UnmarshalJSON, looking its code and the codes of its inner errors up in r.
+func (r *Registry) FromJSON(data []byte) (*AsertoError, error) {
+	var result jsonError
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Version > JSONVersion {
+		return nil, errors.Wrapf(ErrUnsupportedJSONVersion, "%d", result.Version)
+	}
+
+	if result.Code == "" {
+		return nil, errors.New("missing error code")
+	}
+
+	return result.asertoError(r), nil
+}
+
+func newJSONError(e *AsertoError) *jsonError {
+	attributes := e.metadata(grpcSink)
+
+	var sensitive []string
+	if keys, ok := attributes[sensitiveKeysMetadata]; ok {
+		sensitive = strings.Split(keys, ",")
+
+		delete(attributes, sensitiveKeysMetadata)
+	}
+
+	result := &jsonError{
+		Code:       e.Code,
+		Message:    e.Message,
+		Error:      e.errorString(grpcSink),
+		GRPCCode:   code.Code_name[int32(e.StatusCode)], //nolint:gosec // status codes are small positive numbers.
+		HTTPCode:   e.HTTPCode,
+		Attributes: attributes,
+		Sensitive:  sensitive,
+		Retryable:  e.retryable,
+	}
+
+	if e.retryAfter > 0 {
+		result.RetryAfter = e.retryAfter.String()
+	}
+
+	for _, v := range e.violations {
+		result.FieldViolations = append(result.FieldViolations, jsonFieldViolation(v))
+	}
+
+	for _, err := range e.errs {
+		result.Causes = append(result.Causes, newJSONCause(err))
+	}
+
+	return result
+}
+
+func newJSONCause(err error) *jsonError {
+	if aerr, ok := err.(*AsertoError); ok { //nolint:errorlint // only the error itself is encoded as an AsertoError.
+		return newJSONError(aerr)
+	}
+
+	result := &jsonError{Message: errorMessage(grpcSink, err)}
+
+	// keep wrapped AsertoErrors reachable so their fields survive the round-trip.
+	walk(err, 0, func(inner error, _ int) bool {
+		aerr, ok := inner.(*AsertoError) //nolint:errorlint // the tree is traversed by walk.
+		if ok {
+			result.Causes = append(result.Causes, newJSONError(aerr))
+		}
+
+		return !ok
+	})
+
+	return result
+}
+
+func (j *jsonError) asertoError(r *Registry) *AsertoError {
+	statusCode := codes.Unknown
+	if value, ok := code.Code_value[j.GRPCCode]; ok {
+		statusCode = codes.Code(value) //nolint:gosec // the value is a valid status code.
+	}
+
+	var result *AsertoError
+
+	if registered, ok := r.Lookup(j.Code); ok {
+		result = registered.copy()
+	} else {
+		result = newAsertoError(j.Code, statusCode, j.HTTPCode, j.Message)
+		result.unregistered = true
+	}
+
+	result.StatusCode = statusCode
+	result.HTTPCode = j.HTTPCode
+	result.retryable = result.retryable || j.Retryable
+
+	if delay, err := time.ParseDuration(j.RetryAfter); err == nil {
+		result.retryAfter = delay
+	}
+
+	data := make(map[string]any, len(j.Attributes))
+	for key, value := range j.Attributes {
+		data[key] = value
+	}
+
+	if len(j.Sensitive) > 0 {
+		markSensitive(data, map[string]string{sensitiveKeysMetadata: strings.Join(j.Sensitive, ",")})
+	}
+
+	result.data = attributesFromMap(data)
+
+	for _, v := range j.FieldViolations {
+		result.violations = append(result.violations, FieldViolation(v))
+	}
+
+	for _, cause := range j.Causes {
+		result.errs = append(result.errs, cause.cause(r))
+	}
+
+	result.errs = slices.Clip(result.errs)
+
+	return result
+}
+
+func (j *jsonError) cause(r *Registry) error {
+	if j.Code != "" {
+		return j.asertoError(r)
+	}
+
+	result := &wireError{msg: j.Message}
+	for _, cause := range j.Causes {
+		result.causes = append(result.causes, cause.cause(r))
+	}
+
+	return result
+}
@@ -0,0 +1,164 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func jsonRoundTrip(t *testing.T, err *cerr.AsertoError) *cerr.AsertoError {
+	t.Helper()
+
+	buf, marshalErr := json.Marshal(err)
+	require.NoError(t, marshalErr)
+
+	received := &cerr.AsertoError{}
+	require.NoError(t, json.Unmarshal(buf, received))
+
+	return received
+}
+
+func TestMarshalJSONWireFormat(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrNotFound.Str("object_id", "1234").Int("count", 3).Msg("lookup failed").Err(errors.New("boom"))
+
+	buf, marshalErr := json.Marshal(err)
+	assert.NoError(marshalErr)
+	assert.JSONEq(`{
+		"version": 1,
+		"code": "E10001",
+		"message": "not found",
+		"error": "E10001 not found: lookup failed: boom",
+		"grpc_code": "NOT_FOUND",
+		"http_code": 404,
+		"attributes": {"object_id": "1234", "count": "3", "msg": "lookup failed"},
+		"causes": [{"message": "boom"}]
+	}`, string(buf))
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	assert := require.New(t)
+
+	sent := ErrNotFound.Str("object_id", "1234").
+		Int("count", 3).
+		Err(ErrAlreadyExists.Str("key", "value")).
+		Err(errors.Wrap(ErrObjectTypeNotFound.Str("object_id", "5678"), "wrapped")).
+		FieldViolation("user.email", "must be a valid email address").
+		RetryAfter(1500 * time.Millisecond).
+		WithGRPCStatus(codes.Unavailable).
+		WithHTTPStatus(http.StatusServiceUnavailable)
+
+	received := jsonRoundTrip(t, sent)
+	assert.False(received.Unregistered())
+	assert.True(errors.Is(received, ErrNotFound))
+	assert.Equal(sent.Error(), received.Error())
+	assert.Equal(sent.Data(), received.Data())
+	assert.Equal(codes.Unavailable, received.StatusCode)
+	assert.Equal(http.StatusServiceUnavailable, received.HTTPCode)
+	assert.Equal(sent.FieldViolations(), received.FieldViolations())
+
+	count, ok := received.GetInt("count")
+	assert.True(ok)
+	assert.Equal(3, count)
+
+	delay, ok := cerr.RetryDelay(received)
+	assert.True(ok)
+	assert.Equal(1500*time.Millisecond, delay)
+
+	causes := received.Unwrap()
+	assert.Len(causes, 2)
+	assert.True(errors.Is(causes[0], ErrAlreadyExists))
+	assert.Equal("value", causes[0].(*cerr.AsertoError).Data()["key"])
+	assert.Equal("wrapped: E10020 object 5678 of type {{ object_type }} not found", causes[1].Error())
+	assert.True(errors.Is(causes[1], ErrObjectTypeNotFound))
+	assert.Equal("1234", received.Fields()["object_id"], "attributes of outer errors take precedence")
+
+	var inner *cerr.AsertoError
+	assert.True(errors.As(causes[1], &inner))
+	assert.Equal("5678", inner.Data()["object_id"])
+}
+
+func TestJSONUnregisteredCode(t *testing.T) {
+	assert := require.New(t)
+
+	sent := cerr.NewRegistry().MustRegister("E20050", codes.FailedPrecondition, http.StatusPreconditionFailed, "tenant {{tenant}} is suspended").
+		Str("tenant", "acme")
+
+	received := jsonRoundTrip(t, sent)
+	assert.True(received.Unregistered())
+	assert.Equal(sent.Message, received.Message)
+	assert.Equal(sent.Error(), received.Error())
+	assert.Equal(codes.FailedPrecondition, received.StatusCode)
+	assert.Equal(http.StatusPreconditionFailed, received.HTTPCode)
+}
+
+func TestJSONSensitiveAttributes(t *testing.T) {
+	assert := require.New(t)
+
+	buf, err := json.Marshal(ErrNotFound.Sensitive("token", "s3cr3t"))
+	assert.NoError(err)
+	assert.NotContains(string(buf), "s3cr3t")
+
+	setRedactionPolicy(t, cerr.RedactionPolicy{Log: cerr.RedactMask, GRPC: cerr.RedactNone, HTTP: cerr.RedactMask})
+
+	received := jsonRoundTrip(t, ErrNotFound.Sensitive("token", "s3cr3t"))
+	assert.Equal("s3cr3t", received.Data()["token"])
+	assert.Equal(cerr.RedactedValue, received.Fields()["token"])
+}
+
+func TestJSONErrorStringRedaction(t *testing.T) {
+	assert := require.New(t)
+
+	setRedactionPolicy(t, cerr.RedactionPolicy{Log: cerr.RedactNone, GRPC: cerr.RedactMask, HTTP: cerr.RedactMask})
+
+	userNotFound := cerr.NewRegistry().MustRegister("E20051", codes.NotFound, http.StatusNotFound, "user {{email}} not found")
+	sent := userNotFound.Sensitive("email", "alice@example.com").Err(ErrNotFound.Sensitive("msg", "token s3cr3t"))
+
+	assert.Contains(sent.Error(), "alice@example.com")
+
+	buf, err := json.Marshal(sent)
+	assert.NoError(err)
+	assert.NotContains(string(buf), "alice@example.com")
+	assert.NotContains(string(buf), "s3cr3t")
+
+	var body map[string]any
+	assert.NoError(json.Unmarshal(buf, &body))
+	assert.Equal("E20051 user [REDACTED] not found: E10001 not found: [REDACTED]", body["error"])
+}
+
+func TestJSONEmbedded(t *testing.T) {
+	assert := require.New(t)
+
+	type job struct {
+		ID    string            `json:"id"`
+		Error *cerr.AsertoError `json:"error,omitempty"`
+	}
+
+	buf, err := json.Marshal(job{ID: "job-1", Error: ErrNotFound.Msg("bla")})
+	assert.NoError(err)
+
+	var received job
+	assert.NoError(json.Unmarshal(buf, &received))
+	assert.Equal("job-1", received.ID)
+	assert.Equal("E10001 not found: bla", received.Error.Error())
+
+	buf, err = json.Marshal(job{ID: "job-2"})
+	assert.NoError(err)
+	assert.JSONEq(`{"id": "job-2"}`, string(buf))
+}
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	assert := require.New(t)
+
+	aerr := &cerr.AsertoError{}
+	assert.True(errors.Is(json.Unmarshal([]byte(`{"version": 2, "code": "E10001"}`), aerr), cerr.ErrUnsupportedJSONVersion))
+	assert.Error(json.Unmarshal([]byte(`{"version": 1, "message": "bla"}`), aerr))
+	assert.Error(json.Unmarshal([]byte(`"bla"`), aerr))
+}
@@ -0,0 +1,228 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// LocaleMetadataKey is the gRPC metadata key holding the preferred locales of the client,
+	// formatted like the HTTP Accept-Language header.
+	LocaleMetadataKey = "accept-language"
+
+	// gatewayLocaleMetadataKey is the gRPC metadata key of the Accept-Language header forwarded by grpc-gateway.
+	gatewayLocaleMetadataKey = "grpcgateway-accept-language"
+)
+
+var defaultCatalog = NewCatalog() //nolint:gochecknoglobals
+
+// Catalog holds the translations of the messages of AsertoErrors, keyed by error code and locale.
+// Translations can reference the attributes of the error using {{key}} placeholders.
+// A Catalog is safe for concurrent use.
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string][]translation
+}
+
+type translation struct {
+	tag     language.Tag
+	message string
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{messages: make(map[string][]translation)}
+}
+
+// DefaultCatalog returns the catalog used to localize the messages of AsertoErrors.
+func DefaultCatalog() *Catalog {
+	return defaultCatalog
+}
+
+// Add adds the translation in locale of the message of the errors with the given code.
+// A translation previously added for the same code and locale is replaced.
+// An error wrapping ErrInvalidTemplate is returned if the translation is not a valid template.
+func (c *Catalog) Add(code, locale, message string) error {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return errors.Wrapf(err, "invalid locale %q", locale)
+	}
+
+	if _, err := placeholders(message); err != nil {
+		return errors.Wrapf(err, "%s %s", code, locale)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, t := range c.messages[code] {
+		if t.tag == tag {
+			c.messages[code][i].message = message
+
+			return nil
+		}
+	}
+
+	c.messages[code] = append(c.messages[code], translation{tag: tag, message: message})
+
+	return nil
+}
+
+// MustAdd is like Add but panics if the locale or the translation is invalid.
+func (c *Catalog) MustAdd(code, locale, message string) {
+	if err := c.Add(code, locale, message); err != nil {
+		panic(err)
+	}
+}
+
+// Message returns the translation of the message of the errors with the given code that best matches locales,
+// and the locale of that translation. Each locale is either a BCP 47 language tag or an Accept-Language header value.
+func (c *Catalog) Message(code string, locales ...string) (message, locale string, ok bool) {
+	var preferred []language.Tag
+
+	for _, l := range locales {
+		tags, _, err := language.ParseAcceptLanguage(l)
+		if err == nil {
+			preferred = append(preferred, tags...)
+		}
+	}
+
+	if len(preferred) == 0 {
+		return "", "", false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	translations := c.messages[code]
+	if len(translations) == 0 {
+		return "", "", false
+	}
+
+	supported := make([]language.Tag, len(translations))
+	for i, t := range translations {
+		supported[i] = t.tag
+	}
+
+	_, index, confidence := language.NewMatcher(supported).Match(preferred...)
+	if confidence == language.No {
+		return "", "", false
+	}
+
+	return translations[index].message, translations[index].tag.String(), true
+}
+
+type localeKey struct{}
+
+// WithLocale returns a copy of ctx carrying the preferred locales of the client,
+// either a BCP 47 language tag or an Accept-Language header value.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext returns the preferred locales of the client set with WithLocale,
+// or found in the incoming gRPC metadata under LocaleMetadataKey or forwarded by grpc-gateway
+// from the Accept-Language header. It returns an empty string if the client has no preference.
+func LocaleFromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+		return locale
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	for _, key := range []string{LocaleMetadataKey, gatewayLocaleMetadataKey} {
+		if values := md.Get(key); len(values) > 0 {
+			return strings.Join(values, ",")
+		}
+	}
+
+	return ""
+}
+
+// LocaleFromRequest returns the preferred locales of the client listed in the Accept-Language header of r.
+func LocaleFromRequest(r *http.Request) string {
+	return strings.Join(r.Header.Values("Accept-Language"), ",")
+}
+
+// Locale returns a copy of the error whose gRPC status carries the translation of its message in locale
+// as errdetails.LocalizedMessage. The Message of the error is left untouched.
+func (e *AsertoError) Locale(locale string) *AsertoError {
+	c := e.copy()
+	c.locale = locale
+
+	if locale != e.locale {
+		c.localized = nil
+	}
+
+	return c
+}
+
+// LocalizedMessage returns the translation of the message of the error in locale, with its placeholders
+// replaced by the attributes of the error. The rendered Message of the error is returned if there is no translation.
+func (e *AsertoError) LocalizedMessage(locale string) string {
+	if localized := e.localizedMessage(logSink, locale); localized != nil {
+		return localized.GetMessage()
+	}
+
+	return e.message(logSink)
+}
+
+// localizedMessage returns the translation of the message of the error in locale, redacted for sink s.
+// A translation received in a gRPC status is used when the default catalog has none.
+func (e *AsertoError) localizedMessage(s sink, locale string) *errdetails.LocalizedMessage {
+	if locale == "" {
+		return nil
+	}
+
+	if message, tag, ok := defaultCatalog.Message(e.Code, locale); ok {
+		return &errdetails.LocalizedMessage{
+			Locale:  tag,
+			Message: expandTemplate(message, e.attributeLookup(s)),
+		}
+	}
+
+	return e.localized
+}
+
+// localizeStatus returns a copy of st carrying localized as its only errdetails.LocalizedMessage.
+func localizeStatus(st *status.Status, localized *errdetails.LocalizedMessage) *status.Status {
+	detail, err := anypb.New(localized)
+	if err != nil {
+		return st
+	}
+
+	pb := st.Proto()
+	details := pb.GetDetails()[:0]
+
+	for _, d := range pb.GetDetails() {
+		if !d.MessageIs(localized) {
+			details = append(details, d)
+		}
+	}
+
+	pb.Details = append(details, detail)
+
+	return status.FromProto(pb)
+}
+
+// statusLocalizedMessage returns the first errdetails.LocalizedMessage of st.
+func statusLocalizedMessage(st *status.Status) *errdetails.LocalizedMessage {
+	for _, detail := range st.Details() {
+		if localized, ok := detail.(*errdetails.LocalizedMessage); ok {
+			return localized
+		}
+	}
+
+	return nil
+}
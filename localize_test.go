@@ -0,0 +1,165 @@
+package errors_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var ErrObjectNotFound = newErr("E10010", codes.NotFound, http.StatusNotFound, "object not found")
+
+func addTranslations(t *testing.T) {
+	t.Helper()
+
+	cerr.DefaultCatalog().MustAdd(ErrObjectNotFound.Code, "fr", "l'objet {{object_id}} est introuvable")
+	cerr.DefaultCatalog().MustAdd(ErrObjectNotFound.Code, "de", "Objekt {{ object_id }} nicht gefunden")
+}
+
+func localizedMessage(st *status.Status) *errdetails.LocalizedMessage {
+	for _, detail := range st.Details() {
+		if localized, ok := detail.(*errdetails.LocalizedMessage); ok {
+			return localized
+		}
+	}
+
+	return nil
+}
+
+func TestCatalogMessage(t *testing.T) {
+	assert := require.New(t)
+
+	catalog := cerr.NewCatalog()
+	assert.NoError(catalog.Add("E10010", "fr", "objet introuvable"))
+	assert.NoError(catalog.Add("E10010", "pt-BR", "objeto não encontrado"))
+	assert.Error(catalog.Add("E10010", "not a locale!", "bla"))
+
+	message, locale, ok := catalog.Message("E10010", "fr-CA")
+	assert.True(ok)
+	assert.Equal("objet introuvable", message)
+	assert.Equal("fr", locale)
+
+	message, locale, ok = catalog.Message("E10010", "ja, pt-BR;q=0.8, fr;q=0.5")
+	assert.True(ok)
+	assert.Equal("objeto não encontrado", message)
+	assert.Equal("pt-BR", locale)
+
+	_, _, ok = catalog.Message("E10010", "ja")
+	assert.False(ok)
+
+	_, _, ok = catalog.Message("E10011", "fr")
+	assert.False(ok)
+
+	assert.NoError(catalog.Add("E10010", "fr", "l'objet est introuvable"))
+	message, _, _ = catalog.Message("E10010", "fr")
+	assert.Equal("l'objet est introuvable", message)
+}
+
+func TestLocalizedMessage(t *testing.T) {
+	assert := require.New(t)
+	addTranslations(t)
+
+	err := ErrObjectNotFound.Str("object_id", "1234")
+	assert.Equal("l'objet 1234 est introuvable", err.LocalizedMessage("fr-FR"))
+	assert.Equal("Objekt 1234 nicht gefunden", err.LocalizedMessage("de"))
+	assert.Equal("object not found", err.LocalizedMessage("ja"))
+	assert.Equal("E10010 object not found", err.Error())
+}
+
+func TestLocalizedGRPCStatus(t *testing.T) {
+	assert := require.New(t)
+	addTranslations(t)
+
+	err := ErrObjectNotFound.Str("object_id", "1234")
+	assert.Nil(localizedMessage(err.GRPCStatus()))
+
+	st := err.Locale("fr").GRPCStatus()
+	assert.Equal("object not found", st.Message())
+
+	localized := localizedMessage(st)
+	assert.NotNil(localized)
+	assert.Equal("fr", localized.GetLocale())
+	assert.Equal("l'objet 1234 est introuvable", localized.GetMessage())
+
+	received := cerr.FromGRPCStatus(*st)
+	assert.Equal(err.Error(), received.Error())
+	assert.Equal("l'objet 1234 est introuvable", received.LocalizedMessage("fr"))
+	assert.Equal(localized.GetMessage(), localizedMessage(received.GRPCStatus()).GetMessage())
+}
+
+func TestLocalizedMessageRedactsSensitiveAttributes(t *testing.T) {
+	assert := require.New(t)
+	addTranslations(t)
+
+	st := ErrObjectNotFound.Sensitive("object_id", "1234").Locale("fr").GRPCStatus()
+	assert.Equal("l'objet [REDACTED] est introuvable", localizedMessage(st).GetMessage())
+}
+
+func TestLocaleFromContext(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Empty(cerr.LocaleFromContext(t.Context()))
+	assert.Equal("fr", cerr.LocaleFromContext(cerr.WithLocale(t.Context(), "fr")))
+
+	ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(cerr.LocaleMetadataKey, "de"))
+	assert.Equal("de", cerr.LocaleFromContext(ctx))
+
+	ctx = metadata.NewIncomingContext(t.Context(), metadata.Pairs("grpcgateway-accept-language", "fr-CA, fr;q=0.8"))
+	assert.Equal("fr-CA, fr;q=0.8", cerr.LocaleFromContext(ctx))
+}
+
+func TestServerInterceptorsLocalize(t *testing.T) {
+	assert := require.New(t)
+	addTranslations(t)
+
+	conn := newTestConn(t, func(context.Context) error {
+		return ErrObjectNotFound.Str("object_id", "1234")
+	}, []grpc.ServerOption{grpc.UnaryInterceptor(cerr.UnaryServerInterceptor())})
+
+	ctx := metadata.AppendToOutgoingContext(t.Context(), cerr.LocaleMetadataKey, "de-CH")
+	err := conn.Invoke(ctx, testUnaryMethod, &emptypb.Empty{}, &emptypb.Empty{})
+
+	st := status.Convert(err)
+	assert.Equal("object not found", st.Message())
+	assert.Equal("Objekt 1234 nicht gefunden", localizedMessage(st).GetMessage())
+
+	err = conn.Invoke(t.Context(), testUnaryMethod, &emptypb.Empty{}, &emptypb.Empty{})
+	assert.Nil(localizedMessage(status.Convert(err)))
+}
+
+func TestCustomErrorHandlerLocalize(t *testing.T) {
+	assert := require.New(t)
+	addTranslations(t)
+
+	err := ErrObjectNotFound.Str("object_id", "1234").GRPCStatus().Err()
+
+	resp := serveError(t, cerr.CustomErrorHandler, err, http.Header{"Accept-Language": {"fr-FR, en;q=0.5"}})
+	assert.Equal(http.StatusNotFound, resp.StatusCode)
+	assert.Equal("fr", resp.Header.Get("Content-Language"))
+
+	body := readBody(t, resp)
+	assert.Equal("object not found", body["message"])
+	assert.Contains(body["details"], map[string]any{
+		"@type":   "type.googleapis.com/google.rpc.LocalizedMessage",
+		"locale":  "fr",
+		"message": "l'objet 1234 est introuvable",
+	})
+
+	resp = serveError(t, cerr.NewErrorHandler(cerr.WithProblemDetails()), err, http.Header{"Accept-Language": {"de"}})
+	assert.Equal("de", resp.Header.Get("Content-Language"))
+
+	body = readBody(t, resp)
+	assert.Equal("Objekt 1234 nicht gefunden", body["title"])
+	assert.Equal("E10010", body["code"])
+
+	resp = serveError(t, cerr.CustomErrorHandler, err, nil)
+	assert.Empty(resp.Header.Get("Content-Language"))
+}
@@ -0,0 +1,121 @@
+package errors
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	logSamplersMu sync.RWMutex                   //nolint:gochecknoglobals
+	logSamplers   = map[string]zerolog.Sampler{} //nolint:gochecknoglobals
+	suppressed    = map[string]struct{}{}        //nolint:gochecknoglobals
+)
+
+// WithLogLevel sets the level at which LogError logs the registered error.
+func WithLogLevel(level zerolog.Level) ErrorOption {
+	return func(e *AsertoError) {
+		e.logLevel = level
+		e.hasLogLevel = true
+	}
+}
+
+// LogLevel returns the level at which LogError logs the error. Unless it was set with WithLogLevel, it is derived
+// from the gRPC status code of the error: errors caused by the client are logged at info level, transient
+// failures at warn level and server failures at error level.
+func (e *AsertoError) LogLevel() zerolog.Level {
+	if e.hasLogLevel {
+		return e.logLevel
+	}
+
+	switch e.StatusCode {
+	case codes.OK:
+		return zerolog.DebugLevel
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+		codes.FailedPrecondition, codes.OutOfRange, codes.Unauthenticated:
+		return zerolog.InfoLevel
+	case codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted, codes.Unavailable:
+		return zerolog.WarnLevel
+	default:
+		return zerolog.ErrorLevel
+	}
+}
+
+// SetLogSampler samples the logs of the errors with the given code written by LogError. A nil sampler removes sampling.
+func SetLogSampler(code string, sampler zerolog.Sampler) {
+	logSamplersMu.Lock()
+	defer logSamplersMu.Unlock()
+
+	if sampler == nil {
+		delete(logSamplers, code)
+
+		return
+	}
+
+	logSamplers[code] = sampler
+}
+
+// SuppressLogs prevents LogError from logging the errors with the given codes.
+func SuppressLogs(codes ...string) {
+	logSamplersMu.Lock()
+	defer logSamplersMu.Unlock()
+
+	for _, code := range codes {
+		suppressed[code] = struct{}{}
+	}
+}
+
+// UnsuppressLogs removes codes suppressed with SuppressLogs.
+func UnsuppressLogs(codes ...string) {
+	logSamplersMu.Lock()
+	defer logSamplersMu.Unlock()
+
+	for _, code := range codes {
+		delete(suppressed, code)
+	}
+}
+
+// LogError logs the AsertoError represented by err at its log level, with the fields added by MarshalZerologObject.
+// The logger associated with the error is used, as returned by Logger, falling back to the global logger of zerolog/log.
+// Errors whose code is suppressed with SuppressLogs are not logged, and errors whose code has a sampler set with
+// SetLogSampler are logged when sampled.
+func LogError(err error) {
+	if err == nil {
+		return
+	}
+
+	logger := Logger(err)
+	if logger == nil {
+		logger = &log.Logger
+	}
+
+	aerr := NormalizeError(err)
+	logEvent(logger, aerr).Msg(aerr.message(logSink))
+}
+
+// logEvent returns an event of logger holding the fields of aerr at its log level,
+// or nil if the logs of its code are suppressed or not sampled.
+func logEvent(logger *zerolog.Logger, aerr *AsertoError) *zerolog.Event {
+	level := aerr.LogLevel()
+	if !sampleLog(aerr.Code, level) {
+		return nil
+	}
+
+	return logger.WithLevel(level).EmbedObject(aerr)
+}
+
+// sampleLog returns true if an error with the given code must be logged at the given level.
+func sampleLog(code string, level zerolog.Level) bool {
+	logSamplersMu.RLock()
+	defer logSamplersMu.RUnlock()
+
+	if _, ok := suppressed[code]; ok {
+		return false
+	}
+
+	sampler, ok := logSamplers[code]
+
+	return !ok || sampler.Sample(level)
+}
@@ -0,0 +1,185 @@
+package errors_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var ErrRateLimited = newErr("E10050", codes.ResourceExhausted, http.StatusTooManyRequests, "rate limited", cerr.WithLogLevel(zerolog.DebugLevel))
+
+func setGlobalLogger(t *testing.T, logger zerolog.Logger) {
+	t.Helper()
+
+	previous := log.Logger
+	log.Logger = logger
+
+	t.Cleanup(func() { log.Logger = previous })
+}
+
+func suppressLogs(t *testing.T, codes ...string) {
+	t.Helper()
+
+	cerr.SuppressLogs(codes...)
+	t.Cleanup(func() { cerr.UnsuppressLogs(codes...) })
+}
+
+func setLogSampler(t *testing.T, code string, sampler zerolog.Sampler) {
+	t.Helper()
+
+	cerr.SetLogSampler(code, sampler)
+	t.Cleanup(func() { cerr.SetLogSampler(code, nil) })
+}
+
+func logLines(buf *bytes.Buffer) []string {
+	return strings.Split(strings.TrimSpace(buf.String()), "\n")
+}
+
+func TestLogLevel(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(zerolog.InfoLevel, ErrNotFound.LogLevel())
+	assert.Equal(zerolog.InfoLevel, ErrInvalidArgument.LogLevel())
+	assert.Equal(zerolog.WarnLevel, ErrUnavailable.LogLevel())
+	assert.Equal(zerolog.ErrorLevel, cerr.ErrUnknown.LogLevel())
+	assert.Equal(zerolog.ErrorLevel, ErrNotFound.WithGRPCStatus(codes.Internal).LogLevel())
+
+	assert.Equal(zerolog.DebugLevel, ErrRateLimited.LogLevel())
+	assert.Equal(zerolog.DebugLevel, ErrRateLimited.Str("key", "value").Msg("slow down").LogLevel())
+	assert.Equal(zerolog.DebugLevel, cerr.UnwrapAsertoError(ErrRateLimited.GRPCStatus().Err()).LogLevel())
+}
+
+func TestLogErrorUsesErrorLogger(t *testing.T) {
+	assert := require.New(t)
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf)
+
+	cerr.LogError(errors.Wrap(ErrNotFound.Str("object_id", "1234").Ctx(logger.WithContext(t.Context())), "lookup"))
+
+	logs := buf.String()
+	assert.Contains(logs, `"level":"info"`)
+	assert.Contains(logs, `"error":"E10001 not found"`)
+	assert.Contains(logs, `"object_id":"1234"`)
+	assert.Contains(logs, `"message":"not found"`)
+}
+
+func TestLogErrorFallsBackToGlobalLogger(t *testing.T) {
+	assert := require.New(t)
+
+	buf := &bytes.Buffer{}
+	setGlobalLogger(t, zerolog.New(buf))
+
+	cerr.LogError(ErrUnavailable.Str("backend", "directory"))
+	cerr.LogError(errors.New("boom"))
+	cerr.LogError(nil)
+
+	lines := logLines(buf)
+	assert.Len(lines, 2)
+	assert.Contains(lines[0], `"level":"warn"`)
+	assert.Contains(lines[0], `"backend":"directory"`)
+	assert.Contains(lines[1], `"level":"error"`)
+	assert.Contains(lines[1], `"error":"E00000 an unknown error has occurred: boom"`)
+}
+
+func TestLogErrorRespectsLoggerLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	setGlobalLogger(t, zerolog.New(buf).Level(zerolog.InfoLevel))
+
+	cerr.LogError(ErrRateLimited)
+
+	require.Empty(t, buf.String())
+}
+
+func TestLogErrorSuppressed(t *testing.T) {
+	assert := require.New(t)
+
+	buf := &bytes.Buffer{}
+	setGlobalLogger(t, zerolog.New(buf))
+
+	suppressLogs(t, ErrNotFound.Code)
+
+	cerr.LogError(ErrNotFound)
+	cerr.LogError(ErrAlreadyExists)
+	assert.Len(logLines(buf), 1)
+	assert.Contains(buf.String(), `"error":"E10002 already exists"`)
+
+	cerr.UnsuppressLogs(ErrNotFound.Code)
+	cerr.LogError(ErrNotFound)
+	assert.Len(logLines(buf), 2)
+}
+
+func TestLogErrorSampled(t *testing.T) {
+	assert := require.New(t)
+
+	buf := &bytes.Buffer{}
+	setGlobalLogger(t, zerolog.New(buf))
+
+	setLogSampler(t, ErrNotFound.Code, &zerolog.BasicSampler{N: 3})
+
+	for range 6 {
+		cerr.LogError(ErrNotFound)
+		cerr.LogError(ErrAlreadyExists)
+	}
+
+	lines := logLines(buf)
+	assert.Len(lines, 8)
+
+	notFound := 0
+
+	for _, line := range lines {
+		if strings.Contains(line, "E10001") {
+			notFound++
+		}
+	}
+
+	assert.Equal(2, notFound)
+}
+
+func TestServerInterceptorsLogLevels(t *testing.T) {
+	assert := require.New(t)
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf)
+
+	suppressLogs(t, ErrAlreadyExists.Code)
+
+	var fail atomic.Pointer[cerr.AsertoError]
+
+	fail.Store(ErrNotFound)
+
+	conn := newTestConn(t, func(ctx context.Context) error {
+		return fail.Load().Ctx(logger.WithContext(ctx))
+	}, []grpc.ServerOption{
+		grpc.UnaryInterceptor(cerr.UnaryServerInterceptor(cerr.WithLogLevels())),
+		grpc.StreamInterceptor(cerr.StreamServerInterceptor(cerr.WithLogLevels())),
+	})
+
+	_, _ = callTestService(t, conn)
+
+	lines := logLines(buf)
+	assert.Len(lines, 2)
+
+	for _, line := range lines {
+		assert.Contains(line, `"level":"info"`)
+		assert.Contains(line, `"message":"request failed"`)
+	}
+
+	buf.Reset()
+
+	fail.Store(ErrAlreadyExists)
+	_, _ = callTestService(t, conn)
+
+	assert.Empty(buf.String())
+}
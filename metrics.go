@@ -0,0 +1,84 @@
+package errors
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorObservation describes an AsertoError reported to the metrics hook.
+type ErrorObservation struct {
+	// Code is the code of the AsertoError.
+	Code string
+	// StatusCode is the gRPC status code of the error.
+	StatusCode codes.Code
+	// HTTPCode is the HTTP status code of the error.
+	HTTPCode int
+	// Method is the full gRPC method name of the request that failed, or an empty string if it is unknown.
+	Method string
+}
+
+// MetricsHook is notified once of each error leaving the process: the errors returned by handlers, as converted by the
+// server interceptors, and the errors written by CustomErrorHandler. GRPCStatus does not notify the hook, since it is
+// called again by every interceptor reading the status of an error, so the errors of handlers served without the server
+// interceptors are not counted. It must be safe for concurrent use.
+type MetricsHook interface {
+	ObserveError(observation ErrorObservation)
+}
+
+// MetricsHookFunc is a function implementing MetricsHook.
+type MetricsHookFunc func(observation ErrorObservation)
+
+// ObserveError calls f(observation).
+func (f MetricsHookFunc) ObserveError(observation ErrorObservation) {
+	f(observation)
+}
+
+var metricsHook atomic.Pointer[MetricsHook] //nolint:gochecknoglobals
+
+// SetMetricsHook sets the hook notified of the errors sent to clients. A nil hook disables the notifications.
+func SetMetricsHook(hook MetricsHook) {
+	if hook == nil {
+		metricsHook.Store(nil)
+
+		return
+	}
+
+	metricsHook.Store(&hook)
+}
+
+// observeError reports aerr to the metrics hook.
+func observeError(aerr *AsertoError, method string) {
+	hook := metricsHook.Load()
+	if hook == nil {
+		return
+	}
+
+	(*hook).ObserveError(ErrorObservation{
+		Code:       aerr.Code,
+		StatusCode: aerr.StatusCode,
+		HTTPCode:   aerr.HTTPCode,
+		Method:     method,
+	})
+}
+
+// observeGatewayError reports the error written by the gateway error handler to the metrics hook,
+// with the status codes of the response.
+func observeGatewayError(ctx context.Context, r *Registry, err error) {
+	hook := metricsHook.Load()
+	if hook == nil {
+		return
+	}
+
+	method, _ := runtime.RPCMethod(ctx)
+
+	(*hook).ObserveError(ErrorObservation{
+		Code:       r.normalizeError(gatewayError(err)).Code,
+		StatusCode: status.Convert(gatewayError(err)).Code(),
+		HTTPCode:   httpStatus(ctx, err),
+		Method:     method,
+	})
+}
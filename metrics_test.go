@@ -0,0 +1,180 @@
+package errors_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recordingHook is a MetricsHook that records the observed errors.
+type recordingHook struct {
+	mu           sync.Mutex
+	observations []cerr.ErrorObservation
+}
+
+func (h *recordingHook) ObserveError(observation cerr.ErrorObservation) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.observations = append(h.observations, observation)
+}
+
+func (h *recordingHook) recorded() []cerr.ErrorObservation {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.observations
+}
+
+func setMetricsHook(t *testing.T, hook cerr.MetricsHook) {
+	t.Helper()
+
+	cerr.SetMetricsHook(hook)
+	t.Cleanup(func() { cerr.SetMetricsHook(nil) })
+}
+
+func TestMetricsGRPCStatusNotObserved(t *testing.T) {
+	assert := require.New(t)
+
+	hook := &recordingHook{}
+	setMetricsHook(t, hook)
+
+	err := ErrNotFound.WithHTTPStatus(http.StatusGone)
+
+	_ = err.GRPCStatus()
+	_ = status.Code(err)
+	_, _ = status.FromError(errors.Wrap(err, "lookup"))
+
+	assert.Empty(hook.recorded())
+}
+
+func TestMetricsHookFunc(t *testing.T) {
+	assert := require.New(t)
+
+	var observed []string
+
+	setMetricsHook(t, cerr.MetricsHookFunc(func(observation cerr.ErrorObservation) {
+		observed = append(observed, observation.Code)
+	}))
+
+	serveError(t, cerr.CustomErrorHandler, ErrNotFound, nil)
+	serveError(t, cerr.CustomErrorHandler, ErrAlreadyExists, nil)
+
+	cerr.SetMetricsHook(nil)
+
+	serveError(t, cerr.CustomErrorHandler, ErrNotFound, nil)
+
+	assert.Equal([]string{"E10001", "E10002"}, observed)
+}
+
+func TestMetricsServerInterceptors(t *testing.T) {
+	assert := require.New(t)
+
+	hook := &recordingHook{}
+	setMetricsHook(t, hook)
+
+	conn := newTestConn(t, func(ctx context.Context) error {
+		return errors.Wrap(ErrNotFound.Str("object_id", "1234").Ctx(ctx), "lookup")
+	}, withServerInterceptors())
+
+	unaryErr, streamErr := callTestService(t, conn)
+	assert.Equal(codes.NotFound, status.Code(unaryErr))
+	assert.Equal(codes.NotFound, status.Code(streamErr))
+
+	assert.ElementsMatch([]cerr.ErrorObservation{
+		{Code: "E10001", StatusCode: codes.NotFound, HTTPCode: http.StatusNotFound, Method: testUnaryMethod},
+		{Code: "E10001", StatusCode: codes.NotFound, HTTPCode: http.StatusNotFound, Method: testStreamMethod},
+	}, hook.recorded())
+}
+
+func TestMetricsServerInterceptorsPlainError(t *testing.T) {
+	assert := require.New(t)
+
+	hook := &recordingHook{}
+	setMetricsHook(t, hook)
+
+	conn := newTestConn(t, func(context.Context) error {
+		return status.Error(codes.PermissionDenied, "denied")
+	}, withServerInterceptors())
+
+	unaryErr, _ := callTestService(t, conn)
+	assert.Equal(codes.PermissionDenied, status.Code(unaryErr))
+
+	recorded := hook.recorded()
+	assert.Len(recorded, 2)
+	assert.Equal(cerr.ErrorObservation{
+		Code:       cerr.ErrUnknown.Code,
+		StatusCode: codes.PermissionDenied,
+		HTTPCode:   http.StatusForbidden,
+		Method:     testUnaryMethod,
+	}, recorded[0])
+}
+
+func TestMetricsCustomErrorHandler(t *testing.T) {
+	assert := require.New(t)
+
+	const method = "/aserto.errors.test.Test/Call"
+
+	mux := runtime.NewServeMux()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected cerr.ErrorObservation
+	}{
+		{
+			"aserto error",
+			errors.Wrap(ErrNotFound.WithHTTPStatus(http.StatusGone), "lookup"),
+			cerr.ErrorObservation{Code: "E10001", StatusCode: codes.NotFound, HTTPCode: http.StatusGone, Method: method},
+		},
+		{
+			"grpc status",
+			ErrAlreadyExists.GRPCStatus().Err(),
+			cerr.ErrorObservation{Code: "E10002", StatusCode: codes.AlreadyExists, HTTPCode: http.StatusConflict, Method: method},
+		},
+		{
+			"plain status",
+			status.Error(codes.Unavailable, "unavailable"),
+			cerr.ErrorObservation{Code: cerr.ErrUnknown.Code, StatusCode: codes.Unavailable, HTTPCode: http.StatusServiceUnavailable, Method: method},
+		},
+	}
+
+	for _, test := range tests {
+		hook := &recordingHook{}
+		setMetricsHook(t, hook)
+
+		req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/api/v1/objects", http.NoBody)
+
+		ctx, err := runtime.AnnotateContext(req.Context(), mux, req, method)
+		assert.NoError(err, test.name)
+
+		cerr.CustomErrorHandler(ctx, mux, &runtime.JSONPb{}, httptest.NewRecorder(), req, test.err)
+
+		assert.Equal([]cerr.ErrorObservation{test.expected}, hook.recorded(), test.name)
+	}
+}
+
+func TestMetricsProblemDetails(t *testing.T) {
+	assert := require.New(t)
+
+	hook := &recordingHook{}
+	setMetricsHook(t, hook)
+
+	resp := serveError(t, cerr.NewErrorHandler(cerr.WithProblemDetails()), ErrNotFound, nil)
+	assert.Equal(http.StatusNotFound, resp.StatusCode)
+
+	assert.Equal([]cerr.ErrorObservation{{
+		Code:       "E10001",
+		StatusCode: codes.NotFound,
+		HTTPCode:   http.StatusNotFound,
+	}}, hook.recorded())
+}
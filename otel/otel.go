@@ -0,0 +1,173 @@
+// Package otel records the errors of github.com/aserto-dev/errors on OpenTelemetry spans
+// and extracts the IDs of the spans of their contexts.
+package otel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	cerr "github.com/aserto-dev/errors"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// SpanCodeKey is the key of the exception event attribute holding the code of the AsertoError.
+	SpanCodeKey = attribute.Key("aserto.error.code")
+
+	// SpanMessageKey is the key of the exception event attribute holding the rendered message of the AsertoError.
+	SpanMessageKey = attribute.Key("aserto.error.message")
+
+	// SpanAttributePrefix prefixes the keys of the exception event attributes holding the attributes of the AsertoError.
+	SpanAttributePrefix = "aserto.error.attribute."
+
+	// TraceIDKey is the name of the field holding the trace ID extracted by TraceExtractor.
+	TraceIDKey = "trace_id"
+	// SpanIDKey is the name of the field holding the span ID extracted by TraceExtractor.
+	SpanIDKey = "span_id"
+)
+
+var _ cerr.ErrorRecorder = SpanRecorder
+
+// SpanRecorder is an ErrorRecorder recording the errors returned by handlers using RecordSpanError.
+// Use it with the WithErrorRecorder option of the server interceptors.
+func SpanRecorder(ctx context.Context, err error) {
+	RecordSpanError(ctx, err)
+}
+
+// RecordSpanError records the AsertoError represented by err, as returned by the server interceptors, on the active
+// span of the context of the deepest ContextError of err, or on the active span of ctx if err carries no context with
+// a recording span. The status of the span is set from the gRPC status code of the error, the span gets the rpc.grpc.status_code
+// attribute, and an exception event is added carrying the code, message, attributes and stack trace of the error.
+// Sensitive attributes are redacted according to the Log redaction policy.
+// It returns false if err is nil or no recording span was found.
+func RecordSpanError(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	span := spanFromError(err)
+	if span == nil {
+		span = trace.SpanFromContext(ctx)
+	}
+
+	if !span.IsRecording() {
+		return false
+	}
+
+	recordSpan(span, cerr.NormalizeError(err), err)
+
+	return true
+}
+
+// TraceExtractor returns a ContextExtractor extracting the IDs of the OpenTelemetry span of the context
+// as TraceIDKey and SpanIDKey.
+func TraceExtractor() cerr.ContextExtractor {
+	return func(ctx context.Context) map[string]any {
+		spanContext := trace.SpanContextFromContext(ctx)
+		if !spanContext.IsValid() {
+			return nil
+		}
+
+		return map[string]any{
+			TraceIDKey: spanContext.TraceID().String(),
+			SpanIDKey:  spanContext.SpanID().String(),
+		}
+	}
+}
+
+// spanFromError returns the recording span of the context of the deepest ContextError of err, or nil.
+func spanFromError(err error) trace.Span {
+	var span trace.Span
+
+	spanDepth := -1
+
+	walk(err, 0, func(err error, depth int) {
+		if ce, ok := err.(*cerr.ContextError); ok && depth >= spanDepth && ce.Ctx != nil { //nolint:errorlint // the tree is traversed by walk.
+			if ctxSpan := trace.SpanFromContext(ce.Ctx); ctxSpan.IsRecording() {
+				span = ctxSpan
+				spanDepth = depth
+			}
+		}
+	})
+
+	return span
+}
+
+// walk calls fn for err and every error of its tree, depth-first.
+func walk(err error, depth int, fn func(err error, depth int)) {
+	if err == nil {
+		return
+	}
+
+	fn(err, depth)
+
+	switch x := err.(type) { //nolint:errorlint // the tree is traversed explicitly.
+	case interface{ Unwrap() []error }:
+		for _, inner := range x.Unwrap() {
+			walk(inner, depth+1, fn)
+		}
+	case interface{ Unwrap() error }:
+		walk(x.Unwrap(), depth+1, fn)
+	}
+}
+
+// recordSpan records aerr on span. err is the error aerr was extracted from.
+func recordSpan(span trace.Span, aerr *cerr.AsertoError, err error) {
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(aerr.StatusCode))) //nolint:gosec // gRPC codes fit in an int.
+
+	if aerr.StatusCode != codes.OK {
+		span.SetStatus(otelcodes.Error, aerr.Error())
+	}
+
+	fields := aerr.Fields()
+
+	attrs := make([]attribute.KeyValue, 0, len(fields)+3) //nolint:mnd // code, message and stack.
+	attrs = append(attrs,
+		SpanCodeKey.String(aerr.Code),
+		// without a locale, the rendered message of the error itself is returned.
+		SpanMessageKey.String(aerr.LocalizedMessage("")),
+	)
+
+	for key, value := range fields {
+		attrs = append(attrs, spanAttribute(SpanAttributePrefix+key, value))
+	}
+
+	if stackTrace := aerr.StackTrace(); len(stackTrace) > 0 {
+		entries := make([]string, len(stackTrace))
+		for i, frame := range stackTrace {
+			entries[i] = fmt.Sprintf("%+v", frame)
+		}
+
+		attrs = append(attrs, semconv.ExceptionStacktrace(strings.Join(entries, "\n")))
+	}
+
+	span.RecordError(err, trace.WithAttributes(attrs...))
+}
+
+// spanAttribute returns a span attribute holding value, formatted as a string unless it has a matching attribute type.
+func spanAttribute(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int32:
+		return attribute.Int64(key, int64(v))
+	case int64:
+		return attribute.Int64(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case time.Duration:
+		return attribute.String(key, v.String())
+	case time.Time:
+		return attribute.String(key, v.UTC().Format(time.RFC3339))
+	default:
+		return attribute.String(key, fmt.Sprintf("%+v", v))
+	}
+}
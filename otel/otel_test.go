@@ -0,0 +1,178 @@
+package otel_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	cerrotel "github.com/aserto-dev/errors/otel"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testMethod = "/aserto.errors.test.Test/Call"
+
+var (
+	registry         = cerr.NewRegistry()
+	ErrNotFound      = registry.MustRegister("E30001", codes.NotFound, http.StatusNotFound, "not found")
+	ErrAlreadyExists = registry.MustRegister("E30002", codes.AlreadyExists, http.StatusConflict, "already exists")
+)
+
+func newTestTracer(t *testing.T) (trace.Tracer, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	return provider.Tracer("errors_test"), exporter
+}
+
+func exceptionEvent(t *testing.T, span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	t.Helper()
+
+	require.Len(t, span.Events, 1)
+	require.Equal(t, semconv.ExceptionEventName, span.Events[0].Name)
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, attr := range span.Events[0].Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+
+	return attrs
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+
+	return attribute.Value{}, false
+}
+
+func TestRecordSpanError(t *testing.T) {
+	assert := require.New(t)
+
+	cerr.EnableStackTraces(true)
+	t.Cleanup(func() { cerr.EnableStackTraces(false) })
+
+	tracer, exporter := newTestTracer(t)
+
+	ctx, span := tracer.Start(t.Context(), "lookup")
+	err := errors.Wrap(ErrNotFound.
+		Str("object_id", "1234").
+		Int("depth", 3).
+		Bool("cached", false).
+		Sensitive("token", "s3cr3t").
+		Msg("lookup failed").
+		Ctx(ctx), "resolve")
+
+	assert.True(cerrotel.RecordSpanError(context.Background(), err))
+	span.End()
+
+	spans := exporter.GetSpans()
+	assert.Len(spans, 1)
+
+	assert.Equal(otelcodes.Error, spans[0].Status.Code)
+	assert.Equal("E30001 not found: lookup failed", spans[0].Status.Description)
+
+	statusCode, ok := spanAttribute(spans[0], semconv.RPCGRPCStatusCodeKey)
+	assert.True(ok)
+	assert.Equal(int64(codes.NotFound), statusCode.AsInt64())
+
+	event := exceptionEvent(t, spans[0])
+	assert.Equal("resolve: E30001 not found: lookup failed", event[semconv.ExceptionMessageKey].AsString())
+	assert.Equal("E30001", event[cerrotel.SpanCodeKey].AsString())
+	assert.Equal("not found", event[cerrotel.SpanMessageKey].AsString())
+	assert.Equal("1234", event[cerrotel.SpanAttributePrefix+"object_id"].AsString())
+	assert.Equal(int64(3), event[cerrotel.SpanAttributePrefix+"depth"].AsInt64())
+	assert.False(event[cerrotel.SpanAttributePrefix+"cached"].AsBool())
+	assert.Equal(cerr.RedactedValue, event[cerrotel.SpanAttributePrefix+"token"].AsString())
+	assert.Equal("lookup failed", event[cerrotel.SpanAttributePrefix+"msg"].AsString())
+	assert.Contains(event[semconv.ExceptionStacktraceKey].AsString(), "TestRecordSpanError")
+}
+
+func TestRecordSpanErrorFallsBackToContext(t *testing.T) {
+	assert := require.New(t)
+
+	tracer, exporter := newTestTracer(t)
+
+	ctx, span := tracer.Start(t.Context(), "call")
+	assert.True(cerrotel.RecordSpanError(ctx, status.Error(codes.PermissionDenied, "denied")))
+	span.End()
+
+	spans := exporter.GetSpans()
+	assert.Len(spans, 1)
+	assert.Equal(otelcodes.Error, spans[0].Status.Code)
+
+	statusCode, ok := spanAttribute(spans[0], semconv.RPCGRPCStatusCodeKey)
+	assert.True(ok)
+	assert.Equal(int64(codes.PermissionDenied), statusCode.AsInt64())
+
+	event := exceptionEvent(t, spans[0])
+	assert.Equal(cerr.ErrUnknown.Code, event[cerrotel.SpanCodeKey].AsString())
+	_, ok = event[semconv.ExceptionStacktraceKey]
+	assert.False(ok)
+}
+
+func TestRecordSpanErrorWithoutSpan(t *testing.T) {
+	assert := require.New(t)
+
+	assert.False(cerrotel.RecordSpanError(t.Context(), nil))
+	assert.False(cerrotel.RecordSpanError(t.Context(), ErrNotFound))
+	assert.False(cerrotel.RecordSpanError(t.Context(), ErrNotFound.Ctx(t.Context())))
+}
+
+func TestSpanRecorder(t *testing.T) {
+	assert := require.New(t)
+
+	tracer, exporter := newTestTracer(t)
+
+	ctx, span := tracer.Start(t.Context(), testMethod)
+
+	interceptor := cerr.UnaryServerInterceptor(cerr.WithErrorRecorder(cerrotel.SpanRecorder))
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, func(ctx context.Context, _ any) (any, error) {
+		return nil, ErrNotFound.Str("object_id", "1234").Ctx(ctx)
+	})
+	span.End()
+
+	assert.Equal(codes.NotFound, status.Code(err))
+
+	spans := exporter.GetSpans()
+	assert.Len(spans, 1)
+	assert.Equal(otelcodes.Error, spans[0].Status.Code)
+
+	event := exceptionEvent(t, spans[0])
+	assert.Equal("E30001", event[cerrotel.SpanCodeKey].AsString())
+	assert.Equal("1234", event[cerrotel.SpanAttributePrefix+"object_id"].AsString())
+}
+
+func TestTraceExtractor(t *testing.T) {
+	assert := require.New(t)
+
+	cerr.RegisterContextExtractor("trace", cerrotel.TraceExtractor())
+	t.Cleanup(func() { cerr.UnregisterContextExtractor("trace") })
+
+	tracer, _ := newTestTracer(t)
+
+	ctx, span := tracer.Start(t.Context(), "lookup")
+	defer span.End()
+
+	fields := ErrNotFound.Err(ErrAlreadyExists.Ctx(ctx)).Fields()
+	assert.Equal(span.SpanContext().TraceID().String(), fields[cerrotel.TraceIDKey])
+	assert.Equal(span.SpanContext().SpanID().String(), fields[cerrotel.SpanIDKey])
+
+	assert.NotContains(ErrNotFound.Err(ErrAlreadyExists.Ctx(t.Context())).Fields(), cerrotel.TraceIDKey)
+}
@@ -0,0 +1,175 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ProblemJSONContentType is the media type of RFC 9457 problem details.
+	ProblemJSONContentType = "application/problem+json"
+
+	// DefaultProblemTypeBaseURI is the default prefix of the problem type of AsertoErrors.
+	DefaultProblemTypeBaseURI = "urn:aserto:error:"
+
+	// ProblemCodeMember is the problem details extension member holding the code of the AsertoError.
+	ProblemCodeMember = "code"
+
+	problemTypeBlank = "about:blank"
+)
+
+// problemMembers are the members defined by RFC 9457 and this package,
+// attributes of the error with the same name are not rendered as extension members.
+var problemMembers = []string{ //nolint:gochecknoglobals
+	"type", "title", "status", "detail", "instance", ProblemCodeMember, ProblemInvalidParamsMember,
+}
+
+// writeProblem responds with the RFC 9457 problem details of err. The response is written by runtime.DefaultHTTPErrorHandler,
+// like the ones of CustomErrorHandler, so that the server metadata of the request is forwarded as headers and trailers.
+func (h *errorHandler) writeProblem(
+	ctx context.Context,
+	mux *runtime.ServeMux,
+	w http.ResponseWriter,
+	r *http.Request,
+	err error,
+	localized *errdetails.LocalizedMessage,
+) {
+	const fallback = `{"type": "about:blank", "title": "Internal Server Error", "status": 500}`
+
+	code := httpStatus(ctx, err)
+	st := convertStatus(httpSink, gatewayError(err))
+
+	setRetryAfter(w, st)
+
+	if localized != nil {
+		w.Header().Set("Content-Language", localized.GetLocale())
+	}
+
+	buf, merr := json.Marshal(h.problem(err, code, localized))
+	if merr != nil {
+		logger := zerolog.Ctx(ctx)
+		logger.Error().Err(merr).Msg("Failed to marshal problem details")
+
+		w.Header().Del("Trailer")
+		w.Header().Del("Transfer-Encoding")
+		w.Header().Set("Content-Type", ProblemJSONContentType)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = io.WriteString(w, fallback)
+
+		return
+	}
+
+	var httpStatusError runtime.HTTPStatusError
+
+	httpStatusError.Err = redactStatus(httpSink, st).Err()
+	httpStatusError.HTTPStatus = code
+	runtime.DefaultHTTPErrorHandler(ctx, mux, &problemMarshaler{body: buf}, w, r, &httpStatusError)
+}
+
+// problemMarshaler renders the problem details built by writeProblem in place of the gRPC status
+// marshaled by runtime.DefaultHTTPErrorHandler.
+type problemMarshaler struct {
+	runtime.JSONPb
+
+	body []byte
+}
+
+func (m *problemMarshaler) ContentType(any) string {
+	return ProblemJSONContentType
+}
+
+func (m *problemMarshaler) Marshal(any) ([]byte, error) {
+	return m.body, nil
+}
+
+// problem builds the RFC 9457 problem details of err. The type of AsertoErrors is derived from their code,
+// the title is their rendered message, or its translation if localized is set, the detail is their msg attribute,
+// their field violations are listed under ProblemInvalidParamsMember and the other attributes are extension members.
+func (h *errorHandler) problem(err error, code int, localized *errdetails.LocalizedMessage) map[string]any {
+	problem := map[string]any{
+		"status": code,
+	}
+
+	aerr := h.registry.gatewayAsertoError(err)
+	if aerr == nil {
+		problem["type"] = problemTypeBlank
+		problem["title"] = http.StatusText(code)
+
+		if detail := status.Convert(gatewayError(err)).Message(); detail != "" {
+			problem["detail"] = detail
+		}
+
+		return problem
+	}
+
+	data := aerr.metadata(httpSink)
+
+	for key, value := range data {
+		if key == MessageKey || key == sensitiveKeysMetadata || slices.Contains(problemMembers, key) {
+			continue
+		}
+
+		problem[key] = value
+	}
+
+	problem["type"] = h.problemTypeBaseURI + aerr.Code
+	problem["title"] = aerr.message(httpSink)
+	problem[ProblemCodeMember] = aerr.Code
+
+	if localized != nil {
+		problem["title"] = localized.GetMessage()
+	}
+
+	if violations := aerr.FieldViolations(); len(violations) > 0 {
+		problem[ProblemInvalidParamsMember] = problemInvalidParams(violations)
+	}
+
+	if detail := data[MessageKey]; detail != "" {
+		problem["detail"] = detail
+	}
+
+	return problem
+}
+
+// gatewayError returns the error wrapped by a runtime.HTTPStatusError, or err itself.
+func gatewayError(err error) error {
+	var httpStatusError *runtime.HTTPStatusError
+	if errors.As(err, &httpStatusError) {
+		return httpStatusError.Err
+	}
+
+	return err
+}
+
+// gatewayAsertoError returns the AsertoError found in err or decoded from its gRPC status.
+// It returns nil if err is a gRPC status that does not describe an AsertoError.
+func (r *Registry) gatewayAsertoError(err error) *AsertoError {
+	err = gatewayError(err)
+
+	var aerr *AsertoError
+	if errors.As(err, &aerr) {
+		return aerr
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+
+	for _, detail := range st.Details() {
+		if _, isErrInfo := detail.(*errdetails.ErrorInfo); isErrInfo {
+			return r.FromGRPCStatus(*st)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+// Package prometheus provides a MetricsHook counting the errors of github.com/aserto-dev/errors in a Prometheus counter.
+package prometheus
+
+import (
+	"strconv"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrorsMetric is the name of the counter of the errors observed by Metrics.
+const ErrorsMetric = "aserto_errors_total"
+
+// Metrics is a MetricsHook counting errors in a Prometheus counter labeled with the code,
+// the gRPC status code, the HTTP status code and the method of each error. It is a prometheus.Collector
+// that must be registered to expose the counter.
+type Metrics struct {
+	errors *prometheus.CounterVec
+}
+
+var (
+	_ prometheus.Collector = (*Metrics)(nil)
+	_ cerr.MetricsHook     = (*Metrics)(nil)
+)
+
+// NewMetrics returns a Metrics with no observed errors.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: ErrorsMetric,
+			Help: "Number of errors sent to clients, by error code, gRPC status code, HTTP status code and method.",
+		}, []string{"code", "grpc_code", "http_code", "method"}),
+	}
+}
+
+// ObserveError implements MetricsHook.
+func (m *Metrics) ObserveError(observation cerr.ErrorObservation) {
+	m.errors.WithLabelValues(
+		observation.Code,
+		observation.StatusCode.String(),
+		strconv.Itoa(observation.HTTPCode),
+		observation.Method,
+	).Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.errors.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.errors.Collect(ch)
+}
@@ -0,0 +1,65 @@
+package prometheus_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	cerrprom "github.com/aserto-dev/errors/prometheus"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+const testMethod = "/aserto.errors.test.Test/Call"
+
+var (
+	registry         = cerr.NewRegistry()
+	ErrNotFound      = registry.MustRegister("E30001", codes.NotFound, http.StatusNotFound, "not found")
+	ErrAlreadyExists = registry.MustRegister("E30002", codes.AlreadyExists, http.StatusConflict, "already exists")
+)
+
+func handleError(t *testing.T, err error) {
+	t.Helper()
+
+	mux := runtime.NewServeMux()
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/api/v1/objects", http.NoBody)
+
+	ctx, annotateErr := runtime.AnnotateContext(req.Context(), mux, req, testMethod)
+	require.NoError(t, annotateErr)
+
+	cerr.CustomErrorHandler(ctx, mux, &runtime.JSONPb{}, httptest.NewRecorder(), req, err)
+}
+
+func TestMetrics(t *testing.T) {
+	assert := require.New(t)
+
+	metrics := cerrprom.NewMetrics()
+
+	reg := prometheus.NewPedanticRegistry()
+	assert.NoError(reg.Register(metrics))
+
+	cerr.SetMetricsHook(metrics)
+	t.Cleanup(func() { cerr.SetMetricsHook(nil) })
+
+	handleError(t, ErrNotFound)
+	handleError(t, ErrNotFound.Str("object_id", "1234"))
+	handleError(t, ErrAlreadyExists.WithHTTPStatus(http.StatusPreconditionFailed))
+	_ = ErrAlreadyExists.GRPCStatus()
+
+	expected := `
+# HELP aserto_errors_total Number of errors sent to clients, by error code, gRPC status code, HTTP status code and method.
+# TYPE aserto_errors_total counter
+aserto_errors_total{code="E30001",grpc_code="NotFound",http_code="404",method="/aserto.errors.test.Test/Call"} 2
+aserto_errors_total{code="E30002",grpc_code="AlreadyExists",http_code="412",method="/aserto.errors.test.Test/Call"} 1
+`
+	assert.NoError(testutil.GatherAndCompare(reg, strings.NewReader(expected), cerrprom.ErrorsMetric))
+
+	problems, err := testutil.GatherAndLint(reg)
+	assert.NoError(err)
+	assert.Empty(problems)
+}
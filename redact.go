@@ -0,0 +1,271 @@
+package errors
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// RedactedValue replaces the value of sensitive attributes masked by RedactMask.
+	RedactedValue = "[REDACTED]"
+
+	// HashPrefix prefixes the hash of sensitive attributes hashed by RedactHash.
+	HashPrefix = "hmac-sha256:"
+
+	hashKeySize = 32
+
+	// sensitiveKeysMetadata lists the sensitive attributes sent unredacted in a gRPC status,
+	// so that the receiving side keeps treating them as sensitive.
+	sensitiveKeysMetadata = "aserto-sensitive-keys"
+)
+
+// Redaction defines how the value of a sensitive attribute is rendered.
+type Redaction int
+
+const (
+	// RedactNone renders the value as is.
+	RedactNone Redaction = iota
+	// RedactMask replaces the value with RedactedValue.
+	RedactMask
+	// RedactHash replaces the value with its HMAC-SHA256 keyed with the HashKey of the redaction policy,
+	// which allows correlating values without revealing them.
+	RedactHash
+	// RedactOmit removes the attribute.
+	RedactOmit
+)
+
+// RedactionPolicy defines the redaction applied to sensitive attributes in each sink.
+type RedactionPolicy struct {
+	// Log applies to Fields and MarshalZerologObject.
+	Log Redaction
+	// GRPC applies to the ErrorInfo metadata of GRPCStatus.
+	GRPC Redaction
+	// HTTP applies to the bodies rendered by CustomErrorHandler.
+	HTTP Redaction
+	// HashKey is the secret key of the HMAC computed by RedactHash. Services that need to correlate
+	// hashed values must share the same key. If empty, a random key is generated for the process.
+	HashKey []byte
+}
+
+// DefaultRedactionPolicy masks sensitive attributes in all sinks.
+func DefaultRedactionPolicy() RedactionPolicy {
+	return RedactionPolicy{Log: RedactMask, GRPC: RedactMask, HTTP: RedactMask}
+}
+
+type sink int
+
+const (
+	logSink sink = iota
+	grpcSink
+	httpSink
+)
+
+var (
+	redactionPolicy atomic.Pointer[RedactionPolicy] //nolint:gochecknoglobals
+	processHashKey  = newHashKey()                  //nolint:gochecknoglobals
+
+	sensitiveKeysMu sync.RWMutex            //nolint:gochecknoglobals
+	sensitiveKeys   = map[string]struct{}{} //nolint:gochecknoglobals
+)
+
+//nolint:gochecknoinits
+func init() {
+	SetRedactionPolicy(DefaultRedactionPolicy())
+}
+
+// SetRedactionPolicy sets the redaction policy applied to sensitive attributes.
+func SetRedactionPolicy(policy RedactionPolicy) {
+	policy.HashKey = slices.Clone(policy.HashKey)
+	if len(policy.HashKey) == 0 {
+		policy.HashKey = processHashKey
+	}
+
+	redactionPolicy.Store(&policy)
+}
+
+// newHashKey returns a random key used by RedactHash when the redaction policy does not set one.
+func newHashKey() []byte {
+	key := make([]byte, hashKeySize)
+	_, _ = rand.Read(key)
+
+	return key
+}
+
+// RegisterSensitiveKeys marks the attributes with the given keys as sensitive in all errors.
+func RegisterSensitiveKeys(keys ...string) {
+	sensitiveKeysMu.Lock()
+	defer sensitiveKeysMu.Unlock()
+
+	for _, key := range keys {
+		sensitiveKeys[key] = struct{}{}
+	}
+}
+
+// UnregisterSensitiveKeys removes keys registered with RegisterSensitiveKeys.
+func UnregisterSensitiveKeys(keys ...string) {
+	sensitiveKeysMu.Lock()
+	defer sensitiveKeysMu.Unlock()
+
+	for _, key := range keys {
+		delete(sensitiveKeys, key)
+	}
+}
+
+// Sensitive adds an attribute that is redacted according to the redaction policy.
+func (e *AsertoError) Sensitive(key, value string) *AsertoError {
+	return e.with(key, sensitiveValue{value})
+}
+
+// sensitiveValue marks an attribute value as sensitive.
+type sensitiveValue struct {
+	value any
+}
+
+// unwrapValue returns the actual value of an attribute.
+func unwrapValue(value any) any {
+	if v, ok := value.(sensitiveValue); ok {
+		return v.value
+	}
+
+	return value
+}
+
+func isSensitive(key string, value any) bool {
+	if _, ok := value.(sensitiveValue); ok {
+		return true
+	}
+
+	sensitiveKeysMu.RLock()
+	defer sensitiveKeysMu.RUnlock()
+
+	_, ok := sensitiveKeys[key]
+
+	return ok
+}
+
+func (p *RedactionPolicy) forSink(s sink) Redaction {
+	switch s {
+	case grpcSink:
+		return p.GRPC
+	case httpSink:
+		return p.HTTP
+	default:
+		return p.Log
+	}
+}
+
+// redactValue returns the value of the attribute with the given key as rendered in sink s,
+// or false if the attribute must be omitted.
+func redactValue(s sink, key string, value any) (any, bool) {
+	if !isSensitive(key, value) {
+		return value, true
+	}
+
+	value = unwrapValue(value)
+
+	policy := redactionPolicy.Load()
+
+	switch policy.forSink(s) {
+	case RedactNone:
+		return value, true
+	case RedactHash:
+		mac := hmac.New(sha256.New, policy.HashKey)
+		mac.Write([]byte(formatValue(value)))
+
+		return HashPrefix + hex.EncodeToString(mac.Sum(nil)), true
+	case RedactOmit:
+		return nil, false
+	case RedactMask:
+		return RedactedValue, true
+	default:
+		return RedactedValue, true
+	}
+}
+
+// metadata returns the attributes of the error formatted as strings and redacted for sink s.
+// The keys of the sensitive attributes left unredacted are listed under sensitiveKeysMetadata.
+func (e *AsertoError) metadata(s sink) map[string]string {
+	result := make(map[string]string, e.data.len())
+
+	var unredacted []string
+
+	for key, value := range e.data.all() {
+		redacted, ok := redactValue(s, key, value)
+		if !ok {
+			continue
+		}
+
+		if _, isWrapped := value.(sensitiveValue); isWrapped && redactionPolicy.Load().forSink(s) == RedactNone {
+			unredacted = append(unredacted, key)
+		}
+
+		result[key] = formatValue(redacted)
+	}
+
+	if len(unredacted) > 0 {
+		slices.Sort(unredacted)
+		result[sensitiveKeysMetadata] = strings.Join(unredacted, ",")
+	}
+
+	return result
+}
+
+// markSensitive wraps the attributes listed under sensitiveKeysMetadata as sensitive values.
+func markSensitive(data map[string]any, metadata map[string]string) {
+	keys, ok := metadata[sensitiveKeysMetadata]
+	if !ok {
+		return
+	}
+
+	for key := range strings.SplitSeq(keys, ",") {
+		if value, ok := data[key]; ok {
+			data[key] = sensitiveValue{value}
+		}
+	}
+}
+
+// redactStatus returns a copy of st where the sensitive attributes of the ErrorInfo details are redacted for sink s.
+func redactStatus(s sink, st *status.Status) *status.Status {
+	pb := st.Proto()
+
+	for i, detail := range pb.GetDetails() {
+		info := &errdetails.ErrorInfo{}
+		if !detail.MessageIs(info) || detail.UnmarshalTo(info) != nil {
+			continue
+		}
+
+		data := make(map[string]any, len(info.GetMetadata()))
+		for key, value := range info.GetMetadata() {
+			data[key] = value
+		}
+
+		markSensitive(data, info.GetMetadata())
+		delete(data, sensitiveKeysMetadata)
+
+		metadata := make(map[string]string, len(data))
+
+		for key, value := range data {
+			if redacted, ok := redactValue(s, key, value); ok {
+				metadata[key] = formatValue(redacted)
+			}
+		}
+
+		info.Metadata = metadata
+
+		if redacted, err := anypb.New(info); err == nil {
+			pb.Details[i] = redacted
+		}
+	}
+
+	return status.FromProto(pb)
+}
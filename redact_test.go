@@ -0,0 +1,213 @@
+package errors_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func setRedactionPolicy(t *testing.T, policy cerr.RedactionPolicy) {
+	t.Helper()
+
+	cerr.SetRedactionPolicy(policy)
+	t.Cleanup(func() { cerr.SetRedactionPolicy(cerr.DefaultRedactionPolicy()) })
+}
+
+func errorInfoMetadata(err *cerr.AsertoError) map[string]string {
+	info, _ := err.GRPCStatus().Details()[0].(*errdetails.ErrorInfo)
+
+	return info.GetMetadata()
+}
+
+var testHashKey = []byte("test-hash-key")
+
+func hashed(key []byte, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+
+	return cerr.HashPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestSensitiveDefaultPolicy(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrNotFound.Sensitive("token", "s3cr3t").Str("object_id", "1234")
+
+	value, ok := err.GetStr("token")
+	assert.True(ok)
+	assert.Equal("s3cr3t", value)
+	assert.Equal("s3cr3t", err.Data()["token"])
+
+	assert.Equal(cerr.RedactedValue, err.Fields()["token"])
+	assert.Equal("1234", err.Fields()["object_id"])
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf)
+	logger.Error().EmbedObject(err).Send()
+	assert.NotContains(buf.String(), "s3cr3t")
+	assert.Contains(buf.String(), `"token":"[REDACTED]"`)
+
+	assert.Equal(cerr.RedactedValue, errorInfoMetadata(err)["token"])
+	assert.Equal(cerr.RedactedValue, roundTrip(t, err).Data()["token"])
+}
+
+func TestRegisteredSensitiveKeys(t *testing.T) {
+	assert := require.New(t)
+
+	cerr.RegisterSensitiveKeys("email")
+	t.Cleanup(func() { cerr.UnregisterSensitiveKeys("email") })
+
+	err := ErrNotFound.Str("email", "alice@acmecorp.com")
+	assert.Equal(cerr.RedactedValue, err.Fields()["email"])
+	assert.Equal(cerr.RedactedValue, errorInfoMetadata(err)["email"])
+
+	cerr.UnregisterSensitiveKeys("email")
+	assert.Equal("alice@acmecorp.com", err.Fields()["email"])
+}
+
+func TestSensitiveHashAndOmit(t *testing.T) {
+	assert := require.New(t)
+
+	setRedactionPolicy(t, cerr.RedactionPolicy{Log: cerr.RedactHash, GRPC: cerr.RedactOmit, HTTP: cerr.RedactMask, HashKey: testHashKey})
+
+	err := ErrNotFound.Sensitive("subject", "alice")
+	assert.Equal(hashed(testHashKey, "alice"), err.Fields()["subject"])
+	assert.NotContains(errorInfoMetadata(err), "subject")
+	assert.NotContains(roundTrip(t, err).Data(), "subject")
+}
+
+func TestSensitiveMsgOmitted(t *testing.T) {
+	assert := require.New(t)
+
+	setRedactionPolicy(t, cerr.RedactionPolicy{Log: cerr.RedactOmit, GRPC: cerr.RedactOmit, HTTP: cerr.RedactOmit})
+
+	assert.Equal("E10001 not found: boom", ErrNotFound.Sensitive("msg", "secret").Err(errors.New("boom")).Error())
+	assert.Equal("E10001 not found", ErrNotFound.Sensitive("msg", "secret").Error())
+}
+
+func TestSensitiveHashKey(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrNotFound.Sensitive("subject", "alice")
+
+	setRedactionPolicy(t, cerr.RedactionPolicy{Log: cerr.RedactHash, HashKey: []byte("first-key")})
+	first := err.Fields()["subject"]
+	assert.Equal(hashed([]byte("first-key"), "alice"), first)
+
+	setRedactionPolicy(t, cerr.RedactionPolicy{Log: cerr.RedactHash, HashKey: []byte("second-key")})
+	second := err.Fields()["subject"]
+	assert.Equal(hashed([]byte("second-key"), "alice"), second)
+	assert.NotEqual(first, second)
+
+	// without a key, the value is not hashed with a well known key.
+	setRedactionPolicy(t, cerr.RedactionPolicy{Log: cerr.RedactHash})
+	assert.NotEqual(hashed(nil, "alice"), err.Fields()["subject"])
+	assert.Equal(err.Fields()["subject"], err.Fields()["subject"])
+}
+
+func TestSensitiveForwardedOverGRPC(t *testing.T) {
+	assert := require.New(t)
+
+	setRedactionPolicy(t, cerr.RedactionPolicy{Log: cerr.RedactMask, GRPC: cerr.RedactNone, HTTP: cerr.RedactHash})
+
+	err := ErrNotFound.Sensitive("token", "s3cr3t")
+	assert.Equal("s3cr3t", errorInfoMetadata(err)["token"])
+
+	// the receiving side keeps treating the attribute as sensitive.
+	received := roundTrip(t, err)
+	assert.Equal("s3cr3t", received.Data()["token"])
+	assert.Equal(cerr.RedactedValue, received.Fields()["token"])
+
+	forwarded := roundTrip(t, ErrAlreadyExists.Err(received))
+	inner, ok := forwarded.Cause().(*cerr.AsertoError)
+	assert.True(ok)
+	assert.Equal(cerr.RedactedValue, inner.Fields()["token"])
+}
+
+func TestSensitiveHTTPBodies(t *testing.T) {
+	assert := require.New(t)
+
+	setRedactionPolicy(t, cerr.RedactionPolicy{Log: cerr.RedactMask, GRPC: cerr.RedactNone, HTTP: cerr.RedactHash, HashKey: testHashKey})
+
+	err := ErrNotFound.Sensitive("token", "s3cr3t").Err(ErrAlreadyExists.Sensitive("inner_token", "t0k3n")).GRPCStatus().Err()
+
+	resp := serveError(t, cerr.CustomErrorHandler, err, nil)
+	assert.Equal(http.StatusNotFound, resp.StatusCode)
+
+	buf, readErr := io.ReadAll(resp.Body)
+	assert.NoError(readErr)
+	assert.NotContains(string(buf), "s3cr3t")
+	assert.NotContains(string(buf), "t0k3n")
+	assert.Contains(string(buf), hashed(testHashKey, "s3cr3t"))
+	assert.Contains(string(buf), hashed(testHashKey, "t0k3n"))
+	assert.NotContains(string(buf), "aserto-sensitive-keys")
+
+	resp = serveError(t, cerr.NewErrorHandler(cerr.WithProblemDetails()), err, nil)
+
+	body := readBody(t, resp)
+	assert.Equal(hashed(testHashKey, "s3cr3t"), body["token"])
+	assert.NotContains(body, "aserto-sensitive-keys")
+}
+
+func TestSensitiveClientMessages(t *testing.T) {
+	assert := require.New(t)
+
+	setRedactionPolicy(t, cerr.RedactionPolicy{Log: cerr.RedactNone, GRPC: cerr.RedactMask, HTTP: cerr.RedactMask})
+
+	registry := cerr.NewRegistry()
+	userNotFound := registry.MustRegister("E20060", codes.NotFound, http.StatusNotFound, "user {{email}} not found")
+	unauthenticated := registry.MustRegister("E20061", codes.Unauthenticated, http.StatusUnauthorized, "user {{email}} is not authenticated")
+
+	const email = "alice@example.com"
+
+	wrapped := errors.Wrap(userNotFound.Sensitive("email", email), "lookup")
+	assert.Contains(wrapped.Error(), email)
+
+	// inner errors wrapped by other errors.
+	sent := ErrAlreadyExists.Err(wrapped)
+
+	for _, detail := range sent.GRPCStatus().Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			for _, value := range info.GetMetadata() {
+				assert.NotContains(value, email)
+			}
+		}
+	}
+
+	buf, err := json.Marshal(sent)
+	assert.NoError(err)
+	assert.NotContains(string(buf), email)
+
+	// debug info.
+	cerr.EnableDebugInfo(true)
+	t.Cleanup(func() { cerr.EnableDebugInfo(false) })
+
+	info := debugInfo(userNotFound.Sensitive("email", email).WithStack())
+	assert.Equal("E20060 user [REDACTED] not found", info.GetDetail())
+
+	// gateway responses of wrapped errors.
+	denied := errors.Wrap(unauthenticated.Sensitive("email", email), "authenticate")
+
+	for _, handler := range []runtime.ErrorHandlerFunc{cerr.CustomErrorHandler, cerr.NewErrorHandler(cerr.WithProblemDetails())} {
+		resp := serveError(t, handler, denied, nil)
+		assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+		assert.NotContains(resp.Header.Get("WWW-Authenticate"), email)
+
+		body, readErr := io.ReadAll(resp.Body)
+		assert.NoError(readErr)
+		assert.NotContains(string(body), email)
+	}
+}
@@ -0,0 +1,145 @@
+package errors
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrDuplicateCode is returned when registering an error code that is already in use.
+var ErrDuplicateCode = errors.New("duplicate error code")
+
+var defaultRegistry = NewRegistry() //nolint:gochecknoglobals
+
+// ErrorOption configures an AsertoError when it is registered.
+type ErrorOption func(*AsertoError)
+
+// Registry holds a set of well known AsertoErrors indexed by their code.
+// A Registry is safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	errors map[string]*AsertoError
+}
+
+func NewRegistry() *Registry {
+	return &Registry{errors: make(map[string]*AsertoError)}
+}
+
+// DefaultRegistry returns the registry used by NewAsertoError and CodeToAsertoError.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Register creates a new AsertoError and adds it to the registry. The message can be a template
+// referencing the attributes of the error with {{key}} placeholders.
+// An error wrapping ErrDuplicateCode is returned if the code is already registered,
+// and an error wrapping ErrInvalidTemplate if a placeholder of the message is malformed.
+func (r *Registry) Register(code string, statusCode codes.Code, httpCode int, msg string, opts ...ErrorOption) (*AsertoError, error) {
+	if _, err := placeholders(msg); err != nil {
+		return nil, errors.Wrapf(err, "%s", code)
+	}
+
+	asertoError := newAsertoError(code, statusCode, httpCode, msg)
+	asertoError.sentinel = true
+
+	for _, opt := range opts {
+		opt(asertoError)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.errors[code]; ok {
+		return nil, errors.Wrapf(ErrDuplicateCode, "%s", code)
+	}
+
+	r.errors[code] = asertoError
+
+	return asertoError, nil
+}
+
+// MustRegister is like Register but panics if the code is already registered or the message is invalid.
+func (r *Registry) MustRegister(code string, statusCode codes.Code, httpCode int, msg string, opts ...ErrorOption) *AsertoError {
+	asertoError, err := r.Register(code, statusCode, httpCode, msg, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return asertoError
+}
+
+// Lookup returns the AsertoError registered with the given code.
+func (r *Registry) Lookup(code string) (*AsertoError, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	asertoError, ok := r.errors[code]
+
+	return asertoError, ok
+}
+
+// All returns all registered errors sorted by code.
+func (r *Registry) All() []*AsertoError {
+	r.mu.RLock()
+	result := make([]*AsertoError, 0, len(r.errors))
+
+	for _, asertoError := range r.errors {
+		result = append(result, asertoError)
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(result, func(a, b *AsertoError) int {
+		return strings.Compare(a.Code, b.Code)
+	})
+
+	return result
+}
+
+// FromGRPCStatus returns an Aserto error based on a given grpcStatus, looking codes up in r. The details that are not of type errdetails.ErrorInfo,
+// errdetails.LocalizedMessage, errdetails.BadRequest or errdetails.RetryInfo are dropped.
+// The error is constructed based on the first errdetails.ErrorInfo, and its inner errors are rebuilt from the
+// details added by AsertoError.GRPCStatus. The returned error is always a new value, registered errors are never modified.
+// If the code of the error is not registered in r, a transient error carrying the code, status code, message and metadata
+// of the status is returned, and its Unregistered method returns true.
+func (r *Registry) FromGRPCStatus(grpcStatus status.Status) *AsertoError {
+	if len(grpcStatus.Details()) == 0 {
+		return ErrUnknown.Msg(grpcStatus.Message())
+	}
+
+	return r.decodeStatus(&grpcStatus)
+}
+
+// UnwrapAsertoError returns the first AsertoError found in the tree of err.
+// If there is none, it tries to construct one from the first gRPC status found in the tree, looking codes up in r.
+func (r *Registry) UnwrapAsertoError(err error) *AsertoError {
+	if err == nil {
+		return nil
+	}
+
+	var aErr *AsertoError
+	if ok := errors.As(err, &aErr); ok {
+		return aErr
+	}
+
+	// If it's not an Aserto error, try to construct one from grpc status.
+	var grpcErr grpcStatusError
+	if errors.As(err, &grpcErr) && grpcErr.GRPCStatus() != nil {
+		if aErr := r.FromGRPCStatus(*grpcErr.GRPCStatus()); aErr != nil {
+			return aErr
+		}
+	}
+
+	return nil
+}
+
+// set adds asertoError to the registry, replacing any error previously registered with the same code.
+func (r *Registry) set(asertoError *AsertoError) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errors[asertoError.Code] = asertoError
+}
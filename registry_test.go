@@ -0,0 +1,211 @@
+package errors_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRegistryRegister(t *testing.T) {
+	assert := require.New(t)
+	registry := cerr.NewRegistry()
+
+	aerr, err := registry.Register("E30001", codes.NotFound, http.StatusNotFound, "not found")
+	assert.NoError(err)
+	assert.Equal("E30001", aerr.Code)
+
+	found, ok := registry.Lookup("E30001")
+	assert.True(ok)
+	assert.Same(aerr, found)
+
+	_, ok = registry.Lookup("E30002")
+	assert.False(ok)
+}
+
+func TestRegistryDuplicateCode(t *testing.T) {
+	assert := require.New(t)
+	registry := cerr.NewRegistry()
+
+	first := registry.MustRegister("E30001", codes.NotFound, http.StatusNotFound, "not found")
+
+	_, err := registry.Register("E30001", codes.AlreadyExists, http.StatusConflict, "already exists")
+	assert.True(errors.Is(err, cerr.ErrDuplicateCode))
+	assert.Contains(err.Error(), "E30001")
+
+	found, _ := registry.Lookup("E30001")
+	assert.Same(first, found)
+
+	assert.Panics(func() {
+		registry.MustRegister("E30001", codes.NotFound, http.StatusNotFound, "not found")
+	})
+}
+
+func TestRegistryAll(t *testing.T) {
+	assert := require.New(t)
+	registry := cerr.NewRegistry()
+
+	registry.MustRegister("E30003", codes.Internal, http.StatusInternalServerError, "internal")
+	registry.MustRegister("E30001", codes.NotFound, http.StatusNotFound, "not found")
+	registry.MustRegister("E30002", codes.AlreadyExists, http.StatusConflict, "already exists")
+
+	all := registry.All()
+	assert.Len(all, 3)
+	assert.Equal("E30001", all[0].Code)
+	assert.Equal("E30002", all[1].Code)
+	assert.Equal("E30003", all[2].Code)
+}
+
+func TestRegistryIsolation(t *testing.T) {
+	assert := require.New(t)
+	registry := cerr.NewRegistry()
+
+	registry.MustRegister("E30001", codes.NotFound, http.StatusNotFound, "not found")
+
+	assert.Nil(cerr.CodeToAsertoError("E30001"))
+
+	_, ok := registry.Lookup(ErrNotFound.Code)
+	assert.False(ok)
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	assert := require.New(t)
+
+	found, ok := cerr.DefaultRegistry().Lookup(ErrNotFound.Code)
+	assert.True(ok)
+	assert.Same(ErrNotFound, found)
+	assert.Same(cerr.ErrUnknown, cerr.CodeToAsertoError(cerr.ErrUnknown.Code))
+}
+
+func TestRegistryConcurrentUse(t *testing.T) {
+	assert := require.New(t)
+	registry := cerr.NewRegistry()
+
+	const workers = 50
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		duplicates int
+	)
+
+	for i := range workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			code := fmt.Sprintf("E4%04d", i%10)
+
+			_, err := registry.Register(code, codes.Internal, http.StatusInternalServerError, "internal")
+			if err != nil {
+				mu.Lock()
+				duplicates++
+				mu.Unlock()
+			}
+
+			registry.Lookup(code)
+			registry.All()
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Len(registry.All(), 10)
+	assert.Equal(workers-10, duplicates)
+}
+
+func TestRegistryFromGRPCStatus(t *testing.T) {
+	assert := require.New(t)
+	registry := cerr.NewRegistry()
+
+	quota := registry.MustRegister("E30001", codes.ResourceExhausted, http.StatusTooManyRequests, "quota {{limit}} exceeded", cerr.WithRetryable())
+	denied := registry.MustRegister("E30002", codes.PermissionDenied, http.StatusForbidden, "denied", cerr.WithRetryable())
+
+	sent := quota.Int("limit", 10).Msg("slow down").Err(denied.Str("subject", "alice"))
+
+	received := registry.FromGRPCStatus(*sent.GRPCStatus())
+	assert.False(received.Unregistered())
+	assert.True(quota.SameAs(received))
+	assert.Equal("quota {{limit}} exceeded", received.Message)
+	assert.Equal(sent.Error(), received.Error())
+	assert.True(cerr.IsRetryable(received))
+
+	inner, ok := received.Cause().(*cerr.AsertoError)
+	assert.True(ok)
+	assert.False(inner.Unregistered())
+	assert.True(denied.SameAs(inner))
+	assert.Equal("alice", inner.Data()["subject"])
+
+	// the default registry does not know the codes of the registry.
+	assert.True(cerr.FromGRPCStatus(*sent.GRPCStatus()).Unregistered())
+
+	unwrapped := registry.UnwrapAsertoError(errors.Wrap(sent.GRPCStatus().Err(), "proxied"))
+	assert.False(unwrapped.Unregistered())
+}
+
+func TestRegistryFromJSON(t *testing.T) {
+	assert := require.New(t)
+	registry := cerr.NewRegistry()
+
+	quota := registry.MustRegister("E30001", codes.ResourceExhausted, http.StatusTooManyRequests, "quota exceeded")
+	denied := registry.MustRegister("E30002", codes.PermissionDenied, http.StatusForbidden, "denied")
+
+	buf, err := json.Marshal(quota.Msg("slow down").Err(denied))
+	assert.NoError(err)
+
+	received, err := registry.FromJSON(buf)
+	assert.NoError(err)
+	assert.False(received.Unregistered())
+	assert.True(quota.SameAs(received))
+
+	inner, ok := received.Cause().(*cerr.AsertoError)
+	assert.True(ok)
+	assert.False(inner.Unregistered())
+	assert.True(denied.SameAs(inner))
+
+	var fromDefault cerr.AsertoError
+	assert.NoError(json.Unmarshal(buf, &fromDefault))
+	assert.True(fromDefault.Unregistered())
+
+	_, err = registry.FromJSON([]byte(`{"message": "missing code"}`))
+	assert.Error(err)
+}
+
+func TestRegistryInterceptors(t *testing.T) {
+	assert := require.New(t)
+	registry := cerr.NewRegistry()
+
+	quota := registry.MustRegister("E30001", codes.ResourceExhausted, http.StatusTooManyRequests, "quota exceeded", cerr.WithRetryable())
+
+	conn := newTestConn(t, func(context.Context) error {
+		// the handler returns the status of the error, as a proxy would.
+		return errors.Wrap(quota.Msg("slow down").GRPCStatus().Err(), "proxied")
+	}, []grpc.ServerOption{
+		grpc.UnaryInterceptor(cerr.UnaryServerInterceptor(cerr.WithServerRegistry(registry))),
+		grpc.StreamInterceptor(cerr.StreamServerInterceptor(cerr.WithServerRegistry(registry))),
+	},
+		grpc.WithUnaryInterceptor(cerr.UnaryClientInterceptor(cerr.WithClientRegistry(registry))),
+		grpc.WithStreamInterceptor(cerr.StreamClientInterceptor(cerr.WithClientRegistry(registry))),
+	)
+
+	unaryErr, streamErr := callTestService(t, conn)
+
+	for _, err := range []error{unaryErr, streamErr} {
+		var received *cerr.AsertoError
+		assert.True(errors.As(err, &received))
+		assert.False(received.Unregistered())
+		assert.True(quota.SameAs(received))
+		assert.Equal(codes.ResourceExhausted, status.Code(err))
+		assert.True(cerr.IsRetryable(err))
+	}
+}
@@ -0,0 +1,108 @@
+package errors
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// WithRetryable marks the registered error as safe to retry.
+func WithRetryable() ErrorOption {
+	return func(e *AsertoError) {
+		e.retryable = true
+	}
+}
+
+// RetryAfter returns a copy of the error that is safe to retry after the given delay.
+// The delay is sent as errdetails.RetryInfo in the gRPC status of the error and as
+// the Retry-After header of HTTP responses.
+func (e *AsertoError) RetryAfter(delay time.Duration) *AsertoError {
+	c := e.Copy()
+	c.retryable = true
+	c.retryAfter = delay
+
+	return c
+}
+
+// IsRetryable returns true if err, or any error in its tree, is an AsertoError registered as retryable
+// or carrying a retry delay, or a gRPC status carrying errdetails.RetryInfo.
+func IsRetryable(err error) bool {
+	_, ok := retryInfo(err)
+
+	return ok
+}
+
+// RetryDelay returns the longest retry delay found in the tree of err.
+// It returns false if err is not retryable, and a zero delay if no error in its tree carries a delay.
+func RetryDelay(err error) (time.Duration, bool) {
+	return retryInfo(err)
+}
+
+// retryInfo walks the tree of err and returns the longest retry delay, and whether any error is retryable.
+func retryInfo(err error) (time.Duration, bool) {
+	var (
+		delay     time.Duration
+		retryable bool
+	)
+
+	walk(err, 0, func(err error, _ int) bool {
+		switch x := err.(type) { //nolint:errorlint // the tree is traversed by walk.
+		case *AsertoError:
+			if x == nil {
+				return false
+			}
+
+			if x.retryable {
+				retryable = true
+				delay = max(delay, x.retryAfter)
+			}
+		case grpcStatusError:
+			if info := statusRetryInfo(x.GRPCStatus()); info != nil {
+				retryable = true
+				delay = max(delay, info.GetRetryDelay().AsDuration())
+			}
+		}
+
+		return true
+	})
+
+	return delay, retryable
+}
+
+// retryInfoDetail returns the retryability of the error and its inner errors as errdetails.RetryInfo,
+// or nil if none of them is retryable.
+func (e *AsertoError) retryInfoDetail() *errdetails.RetryInfo {
+	delay, ok := retryInfo(e)
+	if !ok {
+		return nil
+	}
+
+	return &errdetails.RetryInfo{RetryDelay: durationpb.New(delay)}
+}
+
+// statusRetryInfo returns the first errdetails.RetryInfo of st.
+func statusRetryInfo(st *status.Status) *errdetails.RetryInfo {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info
+		}
+	}
+
+	return nil
+}
+
+// setRetryAfter sets the Retry-After header, in seconds, from the errdetails.RetryInfo of st.
+func setRetryAfter(w http.ResponseWriter, st *status.Status) {
+	info := statusRetryInfo(st)
+	if info == nil || info.GetRetryDelay().AsDuration() <= 0 {
+		return
+	}
+
+	seconds := math.Ceil(info.GetRetryDelay().AsDuration().Seconds())
+	w.Header().Set("Retry-After", strconv.FormatInt(int64(seconds), 10))
+}
@@ -0,0 +1,132 @@
+package errors_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var ErrUnavailable = newErr("E10040", codes.Unavailable, http.StatusServiceUnavailable, "service unavailable", cerr.WithRetryable())
+
+func TestIsRetryable(t *testing.T) {
+	assert := require.New(t)
+
+	assert.True(cerr.IsRetryable(ErrUnavailable))
+	assert.True(cerr.IsRetryable(ErrUnavailable.Msg("backend down")))
+	assert.False(cerr.IsRetryable(ErrNotFound))
+	assert.False(cerr.IsRetryable(errors.New("boom")))
+	assert.False(cerr.IsRetryable(nil))
+
+	assert.True(cerr.IsRetryable(ErrNotFound.RetryAfter(time.Second)))
+	assert.False(cerr.IsRetryable(ErrNotFound), "builders never modify the registered error")
+
+	assert.True(cerr.IsRetryable(errors.Wrap(ErrUnavailable.Ctx(t.Context()), "calling backend")))
+	assert.True(cerr.IsRetryable(cerr.ErrUnknown.Err(ErrUnavailable)))
+
+	st, err := status.New(codes.Unavailable, "down").WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Minute)})
+	assert.NoError(err)
+	assert.True(cerr.IsRetryable(errors.Wrap(st.Err(), "calling backend")))
+	assert.False(cerr.IsRetryable(status.Error(codes.Unavailable, "down")))
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert := require.New(t)
+
+	delay, ok := cerr.RetryDelay(ErrUnavailable)
+	assert.True(ok)
+	assert.Zero(delay)
+
+	_, ok = cerr.RetryDelay(ErrNotFound)
+	assert.False(ok)
+
+	delay, ok = cerr.RetryDelay(cerr.ErrUnknown.Err(ErrUnavailable.RetryAfter(time.Second)).Err(ErrUnavailable.RetryAfter(time.Minute)))
+	assert.True(ok)
+	assert.Equal(time.Minute, delay)
+}
+
+func TestRetryInfoGRPCStatus(t *testing.T) {
+	assert := require.New(t)
+
+	for _, detail := range ErrNotFound.GRPCStatus().Details() {
+		_, isRetryInfo := detail.(*errdetails.RetryInfo)
+		assert.False(isRetryInfo)
+	}
+
+	err := ErrNotFound.RetryAfter(1500 * time.Millisecond)
+
+	var retryInfo *errdetails.RetryInfo
+
+	for _, detail := range err.GRPCStatus().Details() {
+		if d, ok := detail.(*errdetails.RetryInfo); ok {
+			retryInfo = d
+		}
+	}
+
+	assert.NotNil(retryInfo)
+	assert.Equal(1500*time.Millisecond, retryInfo.GetRetryDelay().AsDuration())
+
+	received := roundTrip(t, err)
+	delay, ok := cerr.RetryDelay(received)
+	assert.True(ok)
+	assert.Equal(1500*time.Millisecond, delay)
+
+	received = roundTrip(t, cerr.ErrUnknown.Err(ErrUnavailable.RetryAfter(time.Minute)))
+	delay, ok = cerr.RetryDelay(received)
+	assert.True(ok)
+	assert.Equal(time.Minute, delay)
+}
+
+func TestRetryInfoUnregisteredCode(t *testing.T) {
+	assert := require.New(t)
+
+	sent := cerr.NewRegistry().MustRegister("E20040", codes.Unavailable, http.StatusServiceUnavailable, "busy", cerr.WithRetryable())
+	assert.True(cerr.IsRetryable(roundTrip(t, sent)))
+}
+
+func TestClientInterceptorsRetryable(t *testing.T) {
+	assert := require.New(t)
+
+	conn := newTestConn(t, func(context.Context) error {
+		return ErrUnavailable.RetryAfter(time.Second)
+	}, withServerInterceptors(), withClientInterceptors()...)
+
+	unaryErr, streamErr := callTestService(t, conn)
+
+	for _, err := range []error{unaryErr, streamErr} {
+		delay, ok := cerr.RetryDelay(err)
+		assert.True(ok)
+		assert.Equal(time.Second, delay)
+	}
+
+	conn = newTestConn(t, func(context.Context) error {
+		return ErrNotFound
+	}, []grpc.ServerOption{grpc.UnaryInterceptor(cerr.UnaryServerInterceptor())})
+
+	assert.False(cerr.IsRetryable(conn.Invoke(t.Context(), testUnaryMethod, nil, nil)))
+}
+
+func TestCustomErrorHandlerRetryAfter(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrUnavailable.RetryAfter(1500 * time.Millisecond).GRPCStatus().Err()
+
+	resp := serveError(t, cerr.CustomErrorHandler, err, nil)
+	assert.Equal(http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal("2", resp.Header.Get("Retry-After"))
+
+	resp = serveError(t, cerr.NewErrorHandler(cerr.WithProblemDetails()), err, nil)
+	assert.Equal("2", resp.Header.Get("Retry-After"))
+
+	resp = serveError(t, cerr.CustomErrorHandler, ErrUnavailable.GRPCStatus().Err(), nil)
+	assert.Empty(resp.Header.Get("Retry-After"))
+}
@@ -0,0 +1,159 @@
+package errors
+
+import (
+	"context"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MethodKey is the name of the field holding the full gRPC method name in logs and error data.
+const MethodKey = "method"
+
+// ServerInterceptorOption configures the interceptors returned by UnaryServerInterceptor and StreamServerInterceptor.
+type ServerInterceptorOption func(*serverInterceptorOptions)
+
+type serverInterceptorOptions struct {
+	registry  *Registry
+	recorder  ErrorRecorder
+	logLevels bool
+}
+
+// ErrorRecorder records an error returned by a handler, e.g. on the active trace span.
+// The otel subpackage provides one recording errors on OpenTelemetry spans.
+type ErrorRecorder func(ctx context.Context, err error)
+
+// WithErrorRecorder passes the errors returned by handlers to recorder.
+func WithErrorRecorder(recorder ErrorRecorder) ServerInterceptorOption {
+	return func(o *serverInterceptorOptions) {
+		o.recorder = recorder
+	}
+}
+
+// WithLogLevels logs the errors returned by handlers like LogError, at the log level of their code and subject
+// to the sampling and suppression of their code, instead of always logging them at error level.
+func WithLogLevels() ServerInterceptorOption {
+	return func(o *serverInterceptorOptions) {
+		o.logLevels = true
+	}
+}
+
+// WithServerRegistry looks the codes of the gRPC statuses returned by handlers up in r instead of the default registry.
+func WithServerRegistry(r *Registry) ServerInterceptorOption {
+	return func(o *serverInterceptorOptions) {
+		o.registry = r
+	}
+}
+
+func newServerInterceptorOptions(opts []ServerInterceptorOption) *serverInterceptorOptions {
+	o := &serverInterceptorOptions{registry: defaultRegistry}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that logs the errors returned by handlers
+// and converts them into AsertoError gRPC statuses.
+func UnaryServerInterceptor(opts ...ServerInterceptorOption) grpc.UnaryServerInterceptor {
+	o := newServerInterceptorOptions(opts)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, o.serverError(ctx, info.FullMethod, err)
+		}
+
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that logs the errors returned by handlers
+// and converts them into AsertoError gRPC statuses.
+func StreamServerInterceptor(opts ...ServerInterceptorOption) grpc.StreamServerInterceptor {
+	o := newServerInterceptorOptions(opts)
+
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, stream); err != nil {
+			return o.serverError(stream.Context(), info.FullMethod, err)
+		}
+
+		return nil
+	}
+}
+
+// serverError logs err using the logger associated with the error, or the one of the request context,
+// passes it to the error recorder if any, reports it to the metrics hook, and returns the gRPC status error
+// of the AsertoError it represents, localized in the locale of the client.
+func (o *serverInterceptorOptions) serverError(ctx context.Context, method string, err error) error {
+	aerr := o.registry.normalizeError(err).withContextFields(err)
+
+	logger := Logger(err)
+	if logger == nil {
+		logger = zerolog.Ctx(ctx)
+	}
+
+	var event *zerolog.Event
+	if o.logLevels {
+		event = logEvent(logger, aerr)
+	} else {
+		event = logger.Error().EmbedObject(aerr)
+	}
+
+	event.Str(MethodKey, method).Msg("request failed")
+
+	if o.recorder != nil {
+		o.recorder(ctx, err)
+	}
+
+	if locale := LocaleFromContext(ctx); locale != "" && aerr.locale == "" {
+		aerr = aerr.Locale(locale)
+	}
+
+	observeError(aerr, method)
+
+	return aerr.GRPCStatus().Err()
+}
+
+// NormalizeError returns the AsertoError represented by err, as sent to clients by the server interceptors,
+// carrying the fields extracted from the contexts of err. gRPC statuses and context errors that do not carry
+// an AsertoError keep their status code, any other error is wrapped into ErrUnknown.
+func NormalizeError(err error) *AsertoError {
+	return defaultRegistry.normalizeError(err).withContextFields(err)
+}
+
+// normalizeError returns the AsertoError represented by err.
+// gRPC statuses and context errors that do not carry an AsertoError keep their status code,
+// any other error is wrapped into ErrUnknown.
+func (r *Registry) normalizeError(err error) *AsertoError {
+	var aerr *AsertoError
+	if errors.As(err, &aerr) {
+		return aerr
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		code := status.FromContextError(err).Code()
+
+		return ErrUnknown.Err(err).WithGRPCStatus(code).WithHTTPStatus(runtime.HTTPStatusFromCode(code))
+	}
+
+	if st, ok := status.FromError(err); ok && len(st.Details()) == 0 && st.Code() != codes.Unknown {
+		return fromPlainStatus(st)
+	}
+
+	if aerr := r.UnwrapAsertoError(err); aerr != nil {
+		return aerr
+	}
+
+	return ErrUnknown.Err(err)
+}
+
+// fromPlainStatus wraps a gRPC status that does not carry an AsertoError into ErrUnknown, keeping its status code.
+func fromPlainStatus(st *status.Status) *AsertoError {
+	return ErrUnknown.Msg(st.Message()).WithGRPCStatus(st.Code()).WithHTTPStatus(runtime.HTTPStatusFromCode(st.Code()))
+}
@@ -0,0 +1,237 @@
+package errors_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	testUnaryMethod  = "/aserto.errors.test.Test/Call"
+	testStreamMethod = "/aserto.errors.test.Test/Stream"
+)
+
+// testService returns the error produced by fail from both its unary and server streaming methods.
+type testService struct {
+	fail func(ctx context.Context) error
+}
+
+var testServiceDesc = grpc.ServiceDesc{
+	ServiceName: "aserto.errors.test.Test",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Call",
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			in := &emptypb.Empty{}
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+
+			handler := func(ctx context.Context, _ any) (any, error) {
+				return &emptypb.Empty{}, srv.(*testService).fail(ctx)
+			}
+
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: testUnaryMethod}, handler)
+		},
+	}},
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Stream",
+		ServerStreams: true,
+		Handler: func(srv any, stream grpc.ServerStream) error {
+			if err := stream.SendMsg(&emptypb.Empty{}); err != nil {
+				return err
+			}
+
+			return srv.(*testService).fail(stream.Context())
+		},
+	}},
+}
+
+var testStreamDesc = &grpc.StreamDesc{StreamName: "Stream", ServerStreams: true}
+
+// newTestConn starts a bufconn server running a testService that fails with fail
+// and returns a client connection to it.
+func newTestConn(t *testing.T, fail func(ctx context.Context) error, serverOpts []grpc.ServerOption, dialOpts ...grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+
+	server := grpc.NewServer(serverOpts...)
+	server.RegisterService(&testServiceDesc, &testService{fail: fail})
+
+	go func() { _ = server.Serve(lis) }()
+
+	t.Cleanup(server.Stop)
+
+	dialOpts = append(dialOpts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet", dialOpts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func withServerInterceptors() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(cerr.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(cerr.StreamServerInterceptor()),
+	}
+}
+
+// callTestService invokes the unary and the streaming method and returns their errors.
+func callTestService(t *testing.T, conn *grpc.ClientConn) (error, error) {
+	t.Helper()
+
+	unaryErr := conn.Invoke(t.Context(), testUnaryMethod, &emptypb.Empty{}, &emptypb.Empty{})
+
+	stream, err := conn.NewStream(t.Context(), testStreamDesc, testStreamMethod)
+	require.NoError(t, err)
+	require.NoError(t, stream.CloseSend())
+	require.NoError(t, stream.RecvMsg(&emptypb.Empty{}))
+
+	streamErr := stream.RecvMsg(&emptypb.Empty{})
+
+	return unaryErr, streamErr
+}
+
+func TestServerInterceptorsAsertoError(t *testing.T) {
+	assert := require.New(t)
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf)
+
+	sent := ErrNotFound.Str("object_id", "1234").Msg("missing object")
+	conn := newTestConn(t, func(ctx context.Context) error {
+		return errors.Wrap(sent.Ctx(logger.WithContext(ctx)), "lookup")
+	}, withServerInterceptors())
+
+	unaryErr, streamErr := callTestService(t, conn)
+
+	for _, err := range []error{unaryErr, streamErr} {
+		assert.Equal(codes.NotFound, status.Code(err))
+
+		received := cerr.UnwrapAsertoError(err)
+		assert.True(ErrNotFound.SameAs(received))
+		assert.Equal(sent.Error(), received.Error())
+		assert.Equal(sent.Data(), received.Data())
+	}
+
+	logs := buf.String()
+	assert.Contains(logs, `"level":"error"`)
+	assert.Contains(logs, `"method":"`+testUnaryMethod+`"`)
+	assert.Contains(logs, `"method":"`+testStreamMethod+`"`)
+	assert.Contains(logs, `"error":"E10001 not found: missing object"`)
+	assert.Contains(logs, `"object_id":"1234"`)
+}
+
+func TestServerInterceptorsPlainError(t *testing.T) {
+	assert := require.New(t)
+
+	conn := newTestConn(t, func(context.Context) error {
+		return errors.New("boom")
+	}, withServerInterceptors())
+
+	unaryErr, streamErr := callTestService(t, conn)
+
+	for _, err := range []error{unaryErr, streamErr} {
+		assert.Equal(codes.Internal, status.Code(err))
+
+		received := cerr.UnwrapAsertoError(err)
+		assert.True(cerr.ErrUnknown.SameAs(received))
+		assert.Equal("E00000 an unknown error has occurred: boom", received.Error())
+	}
+}
+
+func TestServerInterceptorsPlainStatus(t *testing.T) {
+	assert := require.New(t)
+
+	conn := newTestConn(t, func(context.Context) error {
+		return status.Error(codes.PermissionDenied, "denied")
+	}, withServerInterceptors())
+
+	unaryErr, streamErr := callTestService(t, conn)
+
+	for _, err := range []error{unaryErr, streamErr} {
+		assert.Equal(codes.PermissionDenied, status.Code(err))
+
+		received := cerr.UnwrapAsertoError(err)
+		assert.True(cerr.ErrUnknown.SameAs(received))
+		assert.Equal(http.StatusForbidden, received.HTTPCode)
+	}
+}
+
+func TestServerInterceptorsContextError(t *testing.T) {
+	assert := require.New(t)
+
+	conn := newTestConn(t, func(ctx context.Context) error {
+		return cerr.WrapContext(context.DeadlineExceeded, ctx, "waiting for backend")
+	}, withServerInterceptors())
+
+	unaryErr, _ := callTestService(t, conn)
+	assert.Equal(codes.DeadlineExceeded, status.Code(unaryErr))
+}
+
+func TestServerInterceptorsErrorRecorder(t *testing.T) {
+	assert := require.New(t)
+
+	var (
+		mu       sync.Mutex
+		recorded []error
+	)
+
+	recorder := func(_ context.Context, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		recorded = append(recorded, err)
+	}
+
+	sent := ErrNotFound.Str("object_id", "1234")
+	conn := newTestConn(t, func(context.Context) error {
+		return sent
+	}, []grpc.ServerOption{
+		grpc.UnaryInterceptor(cerr.UnaryServerInterceptor(cerr.WithErrorRecorder(recorder))),
+		grpc.StreamInterceptor(cerr.StreamServerInterceptor(cerr.WithErrorRecorder(recorder))),
+	})
+
+	unaryErr, streamErr := callTestService(t, conn)
+	assert.Equal(codes.NotFound, status.Code(unaryErr))
+	assert.Equal(codes.NotFound, status.Code(streamErr))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal([]error{sent, sent}, recorded)
+}
+
+func TestServerInterceptorsSuccess(t *testing.T) {
+	assert := require.New(t)
+
+	conn := newTestConn(t, func(context.Context) error {
+		return nil
+	}, withServerInterceptors())
+
+	assert.NoError(conn.Invoke(t.Context(), testUnaryMethod, &emptypb.Empty{}, &emptypb.Empty{}))
+}
@@ -0,0 +1,180 @@
+package errors
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"runtime"
+	"slices"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// SlogErrorKey is the key of the attributes expanded by the handler returned by NewSlogHandler.
+const SlogErrorKey = "err"
+
+type slogLoggerKey struct{}
+
+// ContextWithSlogLogger returns a copy of ctx carrying logger, which SlogLogger retrieves
+// from the ContextErrors created with that context.
+func ContextWithSlogLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, slogLoggerKey{}, logger)
+}
+
+// SlogLogger is the log/slog equivalent of Logger. It retrieves the most inner *slog.Logger associated with
+// an error using ContextWithSlogLogger. The whole tree of the error is searched, and the logger of the deepest
+// ContextError wins.
+func SlogLogger(err error) *slog.Logger {
+	var logger *slog.Logger
+
+	loggerDepth := -1
+
+	walk(err, 0, func(err error, depth int) bool {
+		if ce, ok := err.(*ContextError); ok && depth >= loggerDepth && ce.Ctx != nil { //nolint:errorlint // the tree is traversed by walk.
+			if ctxLogger, ok := ce.Ctx.Value(slogLoggerKey{}).(*slog.Logger); ok && ctxLogger != nil {
+				logger = ctxLogger
+				loggerDepth = depth
+			}
+		}
+
+		return true
+	})
+
+	return logger
+}
+
+// LogValue implements slog.LogValuer. The error is logged as a group holding the error message, its code,
+// its rendered message and the typed attributes of the error and its inner errors, as returned by Fields.
+func (e *AsertoError) LogValue() slog.Value {
+	return slog.GroupValue(e.slogAttrs(e.Error())...)
+}
+
+// slogAttrs returns the attributes logged for the error, using errorMessage as the error message.
+func (e *AsertoError) slogAttrs(errorMessage string) []slog.Attr {
+	fields := e.Fields()
+
+	attrs := make([]slog.Attr, 0, len(fields)+4) //nolint:mnd // error, code, message and stack.
+	attrs = append(attrs,
+		slog.String("error", errorMessage),
+		slog.String("code", e.Code),
+		slog.String("message", e.message(logSink)),
+	)
+
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		attrs = append(attrs, slog.Any(key, fields[key]))
+	}
+
+	if violations := e.FieldViolations(); len(violations) > 0 {
+		group := make([]any, len(violations))
+		for i, v := range violations {
+			group[i] = slog.Group(strconv.Itoa(i), slog.String("field", v.Field), slog.String("description", v.Description))
+		}
+
+		attrs = append(attrs, slog.Group(FieldViolationsKey, group...))
+	}
+
+	if len(e.stack) > 0 {
+		attrs = append(attrs, slog.Any("stack", slogStack(e.stack)))
+	}
+
+	return attrs
+}
+
+// slogStack formats a stack trace as a list of "function file:line" entries.
+func slogStack(stack []uintptr) []string {
+	entries := make([]string, 0, len(stack))
+	frames := runtime.CallersFrames(stack)
+
+	for {
+		frame, more := frames.Next()
+		entries = append(entries, frame.Function+" "+frame.File+":"+strconv.Itoa(frame.Line))
+
+		if !more {
+			break
+		}
+	}
+
+	return entries
+}
+
+// slogHandler is a slog.Handler expanding the AsertoErrors found in SlogErrorKey attributes.
+type slogHandler struct {
+	next slog.Handler
+}
+
+// NewSlogHandler returns a slog.Handler that passes records to next after expanding the values of the
+// SlogErrorKey attributes that carry an AsertoError, wrapped or not, into a group holding the error message
+// and the code and attributes of the AsertoError.
+func NewSlogHandler(next slog.Handler) slog.Handler {
+	return &slogHandler{next: next}
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	expanded := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+
+	record.Attrs(func(attr slog.Attr) bool {
+		expanded.AddAttrs(expandSlogAttr(attr))
+
+		return true
+	})
+
+	return h.next.Handle(ctx, expanded)
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		expanded[i] = expandSlogAttr(attr)
+	}
+
+	return &slogHandler{next: h.next.WithAttrs(expanded)}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	return &slogHandler{next: h.next.WithGroup(name)}
+}
+
+// expandSlogAttr expands attr if it is a SlogErrorKey attribute whose value is an error carrying an AsertoError.
+func expandSlogAttr(attr slog.Attr) slog.Attr {
+	if attr.Key != SlogErrorKey || attr.Value.Kind() != slog.KindAny {
+		return attr
+	}
+
+	err, ok := attr.Value.Any().(error)
+	if !ok || err == nil {
+		return attr
+	}
+
+	aerr := carriedAsertoError(err)
+	if aerr == nil {
+		return attr
+	}
+
+	return slog.Attr{Key: attr.Key, Value: slog.GroupValue(aerr.withContextFields(err).slogAttrs(err.Error())...)}
+}
+
+// carriedAsertoError returns the first AsertoError found in the tree of err, or decoded from the first
+// gRPC status found in the tree if that status carries an AsertoError.
+func carriedAsertoError(err error) *AsertoError {
+	var aerr *AsertoError
+	if errors.As(err, &aerr) {
+		return aerr
+	}
+
+	var grpcErr grpcStatusError
+	if !errors.As(err, &grpcErr) {
+		return nil
+	}
+
+	st := grpcErr.GRPCStatus()
+	if _, ok := statusErrorCode(st); !ok {
+		return nil
+	}
+
+	return defaultRegistry.decodeStatus(st)
+}
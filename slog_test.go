@@ -0,0 +1,166 @@
+package errors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func logJSON(t *testing.T, handler func(*bytes.Buffer) slog.Handler, log func(*slog.Logger)) map[string]any {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	log(slog.New(handler(buf)))
+
+	result := map[string]any{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+
+	return result
+}
+
+func jsonHandler(buf *bytes.Buffer) slog.Handler {
+	return slog.NewJSONHandler(buf, nil)
+}
+
+func expandingHandler(buf *bytes.Buffer) slog.Handler {
+	return cerr.NewSlogHandler(slog.NewJSONHandler(buf, nil))
+}
+
+func TestSlogLogValue(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrNotFound.
+		Str("object_id", "1234").
+		Int("depth", 3).
+		Bool("cached", false).
+		Sensitive("token", "s3cr3t").
+		FieldViolation("user.email", "must be a valid email address").
+		Msg("lookup failed")
+
+	entry := logJSON(t, jsonHandler, func(logger *slog.Logger) {
+		logger.Error("request failed", "err", err)
+	})
+
+	assert.Equal(map[string]any{
+		"error":     "E10001 not found: lookup failed",
+		"code":      "E10001",
+		"message":   "not found",
+		"object_id": "1234",
+		"depth":     float64(3),
+		"cached":    false,
+		"token":     cerr.RedactedValue,
+		"msg":       "lookup failed",
+		"field_violations": map[string]any{
+			"0": map[string]any{"field": "user.email", "description": "must be a valid email address"},
+		},
+	}, entry["err"])
+}
+
+func TestSlogLogValueIncludesInnerAttributes(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrNotFound.Str("object_id", "1234").Err(ErrAlreadyExists.Str("key", "value"))
+
+	entry := logJSON(t, jsonHandler, func(logger *slog.Logger) {
+		logger.Error("request failed", "err", err)
+	})
+
+	group, ok := entry["err"].(map[string]any)
+	assert.True(ok)
+	assert.Equal("E10001", group["code"])
+	assert.Equal("1234", group["object_id"])
+	assert.Equal("value", group["key"])
+}
+
+func TestSlogLoggerWithNilError(t *testing.T) {
+	require.Nil(t, cerr.SlogLogger(nil))
+}
+
+func TestSlogLoggerWithoutLogger(t *testing.T) {
+	require.Nil(t, cerr.SlogLogger(ErrNotFound.Ctx(t.Context())))
+}
+
+func TestSlogLoggerDeepestContextWins(t *testing.T) {
+	assert := require.New(t)
+
+	outer := slog.New(slog.DiscardHandler)
+	inner := slog.New(slog.DiscardHandler)
+
+	err := cerr.WithContext(
+		ErrNotFound.Err(ErrAlreadyExists.Ctx(cerr.ContextWithSlogLogger(t.Context(), inner))),
+		cerr.ContextWithSlogLogger(t.Context(), outer),
+	)
+
+	assert.Same(inner, cerr.SlogLogger(err))
+	assert.Same(outer, cerr.SlogLogger(ErrNotFound.Ctx(cerr.ContextWithSlogLogger(t.Context(), outer))))
+}
+
+func TestSlogHandlerExpandsWrappedErrors(t *testing.T) {
+	assert := require.New(t)
+
+	aerr := ErrNotFound.Str("object_id", "1234")
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"wrapped", errors.Wrap(aerr, "lookup")},
+		{"context", aerr.Ctx(t.Context())},
+		{"grpc status", aerr.GRPCStatus().Err()},
+	}
+
+	for _, test := range tests {
+		entry := logJSON(t, expandingHandler, func(logger *slog.Logger) {
+			logger.Error("request failed", "err", test.err)
+		})
+
+		group, ok := entry["err"].(map[string]any)
+		assert.True(ok, test.name)
+		assert.Equal(test.err.Error(), group["error"], test.name)
+		assert.Equal("E10001", group["code"], test.name)
+		assert.Equal("1234", group["object_id"], test.name)
+	}
+}
+
+func TestSlogHandlerExpandsWithAttrs(t *testing.T) {
+	assert := require.New(t)
+
+	err := errors.Wrap(ErrNotFound.Str("object_id", "1234"), "lookup")
+
+	entry := logJSON(t, expandingHandler, func(logger *slog.Logger) {
+		logger.With("err", err).WithGroup("request").Error("request failed", "id", "42")
+	})
+
+	group, ok := entry["err"].(map[string]any)
+	assert.True(ok)
+	assert.Equal("lookup: E10001 not found", group["error"])
+	assert.Equal("E10001", group["code"])
+	assert.Equal(map[string]any{"id": "42"}, entry["request"])
+}
+
+func TestSlogHandlerLeavesOtherErrors(t *testing.T) {
+	assert := require.New(t)
+
+	entry := logJSON(t, expandingHandler, func(logger *slog.Logger) {
+		logger.Error("request failed",
+			"err", errors.New("boom"),
+			"cause", errors.Wrap(ErrNotFound, "lookup"),
+		)
+	})
+
+	assert.Equal("boom", entry["err"])
+	assert.Equal("lookup: E10001 not found", entry["cause"])
+
+	entry = logJSON(t, expandingHandler, func(logger *slog.Logger) {
+		logger.Error("request failed", "err", status.Error(codes.NotFound, "not found"))
+	})
+
+	assert.Equal("rpc error: code = NotFound desc = not found", entry["err"])
+}
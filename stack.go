@@ -0,0 +1,127 @@
+package errors
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+const (
+	maxStackDepth = 32
+	packagePrefix = "github.com/aserto-dev/errors."
+)
+
+var (
+	stackTracesEnabled atomic.Bool //nolint:gochecknoglobals
+	debugInfoEnabled   atomic.Bool //nolint:gochecknoglobals
+)
+
+// EnableStackTraces toggles the capture of a stack trace when a registered error is first
+// derived using one of the builder methods (Msg, Err, Str, ...).
+func EnableStackTraces(enabled bool) {
+	stackTracesEnabled.Store(enabled)
+}
+
+// EnableDebugInfo toggles the inclusion of the stack trace of errors as errdetails.DebugInfo in their gRPC status.
+// It should only be enabled in development environments, since it exposes implementation details to clients.
+func EnableDebugInfo(enabled bool) {
+	debugInfoEnabled.Store(enabled)
+}
+
+// WithStack returns a copy of the error carrying the stack trace of its caller,
+// regardless of EnableStackTraces.
+func (e *AsertoError) WithStack() *AsertoError {
+	c := e.copy()
+	c.stack = callers()
+
+	return c
+}
+
+// StackTrace returns the stack trace captured when the error was created, if any.
+func (e *AsertoError) StackTrace() errors.StackTrace {
+	if len(e.stack) == 0 {
+		return nil
+	}
+
+	stackTrace := make(errors.StackTrace, len(e.stack))
+	for i, pc := range e.stack {
+		stackTrace[i] = errors.Frame(pc)
+	}
+
+	return stackTrace
+}
+
+// callers returns the program counters of the stack of the caller, skipping the frames of this package.
+func callers() []uintptr {
+	var pcs [maxStackDepth]uintptr
+
+	n := runtime.Callers(2, pcs[:]) //nolint:mnd // skip runtime.Callers and callers.
+	stack := pcs[:n]
+
+	for len(stack) > 0 {
+		fn := runtime.FuncForPC(stack[0] - 1)
+		if fn == nil || !strings.HasPrefix(fn.Name(), packagePrefix) {
+			break
+		}
+
+		stack = stack[1:]
+	}
+
+	return stack
+}
+
+// stackFrames logs a stack trace as an array of objects, in the format used by zerolog/pkgerrors.
+type stackFrames []uintptr
+
+func (s stackFrames) MarshalZerologArray(a *zerolog.Array) {
+	frames := runtime.CallersFrames(s)
+
+	for {
+		frame, more := frames.Next()
+
+		a.Dict(zerolog.Dict().
+			Str("func", funcName(frame.Function)).
+			Str("source", filepath.Base(frame.File)).
+			Int("line", frame.Line),
+		)
+
+		if !more {
+			break
+		}
+	}
+}
+
+// debugInfo returns the stack trace of the error as errdetails.DebugInfo.
+func (e *AsertoError) debugInfo() *errdetails.DebugInfo {
+	entries := make([]string, 0, len(e.stack))
+	frames := runtime.CallersFrames(e.stack)
+
+	for {
+		frame, more := frames.Next()
+		entries = append(entries, fmt.Sprintf("%s\n\t%s:%d", frame.Function, frame.File, frame.Line))
+
+		if !more {
+			break
+		}
+	}
+
+	return &errdetails.DebugInfo{
+		StackEntries: entries,
+		Detail:       e.errorString(grpcSink),
+	}
+}
+
+// funcName removes the path prefix component of a function's name.
+func funcName(name string) string {
+	i := strings.LastIndex(name, "/")
+	name = name[i+1:]
+	i = strings.Index(name, ".")
+
+	return name[i+1:]
+}
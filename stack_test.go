@@ -0,0 +1,109 @@
+package errors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func enableStackTraces(t *testing.T) {
+	t.Helper()
+
+	cerr.EnableStackTraces(true)
+	t.Cleanup(func() { cerr.EnableStackTraces(false) })
+}
+
+func debugInfo(err *cerr.AsertoError) *errdetails.DebugInfo {
+	for _, detail := range err.GRPCStatus().Details() {
+		if info, ok := detail.(*errdetails.DebugInfo); ok {
+			return info
+		}
+	}
+
+	return nil
+}
+
+func TestStackTraceDisabledByDefault(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrNotFound.Msg("bla")
+	assert.Nil(err.StackTrace())
+	assert.NotContains(fmt.Sprintf("%+v", err), "stack:")
+}
+
+func TestStackTraceOnDerive(t *testing.T) {
+	assert := require.New(t)
+	enableStackTraces(t)
+
+	err := ErrNotFound.Msg("bla")
+
+	stack := err.StackTrace()
+	assert.NotEmpty(stack)
+	assert.Equal("TestStackTraceOnDerive", fmt.Sprintf("%n", stack[0]))
+	assert.Equal("stack_test.go", fmt.Sprintf("%s", stack[0]))
+
+	assert.Equal(stack, err.Str("key", "value").Err(ErrAlreadyExists).StackTrace(), "derived errors keep the original stack")
+	assert.Nil(ErrNotFound.StackTrace(), "registered errors never carry a stack")
+
+	formatted := fmt.Sprintf("%+v", err)
+	assert.Contains(formatted, "E10001 not found: bla\n")
+	assert.Contains(formatted, "    stack:\n")
+	assert.Contains(formatted, "errors_test.TestStackTraceOnDerive\n")
+	assert.Contains(formatted, "stack_test.go:")
+}
+
+func TestWithStack(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrNotFound.Msg("bla").WithStack()
+
+	stack := err.StackTrace()
+	assert.NotEmpty(stack)
+	assert.Equal("TestWithStack", fmt.Sprintf("%n", stack[0]))
+}
+
+func TestStackTraceLogged(t *testing.T) {
+	assert := require.New(t)
+	enableStackTraces(t)
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf)
+	logger.Error().EmbedObject(ErrNotFound.Msg("bla")).Send()
+
+	entry := struct {
+		Error string           `json:"error"`
+		Stack []map[string]any `json:"stack"`
+	}{}
+	assert.NoError(json.Unmarshal(buf.Bytes(), &entry))
+
+	assert.Equal("E10001 not found: bla", entry.Error)
+	assert.NotEmpty(entry.Stack)
+	assert.Equal("TestStackTraceLogged", entry.Stack[0]["func"])
+	assert.Equal("stack_test.go", entry.Stack[0]["source"])
+	assert.Positive(entry.Stack[0]["line"])
+}
+
+func TestStackTraceDebugInfo(t *testing.T) {
+	assert := require.New(t)
+	enableStackTraces(t)
+
+	err := ErrNotFound.Msg("bla")
+	assert.Nil(debugInfo(err), "debug info is disabled by default")
+
+	cerr.EnableDebugInfo(true)
+	t.Cleanup(func() { cerr.EnableDebugInfo(false) })
+
+	info := debugInfo(err)
+	assert.NotNil(info)
+	assert.Equal(err.Error(), info.GetDetail())
+	assert.Contains(info.GetStackEntries()[0], "errors_test.TestStackTraceDebugInfo")
+
+	received := roundTrip(t, err.Err(ErrAlreadyExists))
+	assert.Equal(err.Err(ErrAlreadyExists).Error(), received.Error())
+}
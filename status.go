@@ -0,0 +1,384 @@
+package errors
+
+import (
+	"strconv"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+const (
+	// causeReason is the ErrorInfo reason of the details describing the inner errors of an AsertoError.
+	causeReason = "ASERTO_ERROR_CAUSE"
+
+	causeParentMetadata     = "aserto-cause-parent"
+	causeMessageMetadata    = "aserto-cause-message"
+	grpcStatusErrorMetadata = "aserto-grpc-statuscode"
+	templateMetadata        = "aserto-message-template"
+)
+
+// statusEncoder flattens an AsertoError and its inner errors into a list of ErrorInfo details.
+// The first detail describes the AsertoError itself, the following ones describe its inner errors
+// in depth-first order and reference their parent by its position in the list.
+type statusEncoder struct {
+	details []protoadapt.MessageV1
+}
+
+func (enc *statusEncoder) encode(e *AsertoError) {
+	metadata := e.metadata(grpcSink)
+
+	// a status code explicitly set with Str(HTTPStatusErrorMetadata, ...) takes precedence.
+	if _, ok := metadata[HTTPStatusErrorMetadata]; !ok && e.HTTPCode != 0 {
+		metadata[HTTPStatusErrorMetadata] = strconv.Itoa(e.HTTPCode)
+	}
+
+	for _, field := range e.extractedFields() {
+		if _, ok := e.data.get(field.key); ok || !field.metadata {
+			continue
+		}
+
+		if redacted, ok := redactValue(grpcSink, field.key, field.value); ok {
+			metadata[field.key] = formatValue(redacted)
+		}
+	}
+
+	// the status message is rendered, keep the template for receivers that do not know the code.
+	if len(e.Placeholders()) > 0 {
+		metadata[templateMetadata] = e.Message
+	}
+
+	enc.details = append(enc.details, &errdetails.ErrorInfo{
+		Domain:   e.Code,
+		Metadata: metadata,
+	})
+
+	for _, err := range e.errs {
+		enc.encodeCause(0, err)
+	}
+
+	if retryInfo := e.retryInfoDetail(); retryInfo != nil {
+		enc.details = append(enc.details, retryInfo)
+	}
+
+	if badRequest := e.badRequest(); badRequest != nil {
+		enc.details = append(enc.details, badRequest)
+	}
+
+	if localized := e.localizedMessage(grpcSink, e.locale); localized != nil {
+		enc.details = append(enc.details, localized)
+	}
+
+	if debugInfoEnabled.Load() && len(e.stack) > 0 {
+		enc.details = append(enc.details, e.debugInfo())
+	}
+}
+
+func (enc *statusEncoder) encodeCause(parent int, err error) {
+	index := len(enc.details)
+
+	if aerr, ok := err.(*AsertoError); ok { //nolint:errorlint // only the error itself is encoded as an AsertoError.
+		metadata := aerr.metadata(grpcSink)
+		metadata[causeParentMetadata] = strconv.Itoa(parent)
+		metadata[causeMessageMetadata] = aerr.Message
+		metadata[grpcStatusErrorMetadata] = strconv.Itoa(int(aerr.StatusCode))
+		metadata[HTTPStatusErrorMetadata] = strconv.Itoa(aerr.HTTPCode)
+
+		enc.details = append(enc.details, &errdetails.ErrorInfo{
+			Reason:   causeReason,
+			Domain:   aerr.Code,
+			Metadata: metadata,
+		})
+
+		for _, inner := range aerr.errs {
+			enc.encodeCause(index, inner)
+		}
+
+		return
+	}
+
+	enc.details = append(enc.details, &errdetails.ErrorInfo{
+		Reason: causeReason,
+		Metadata: map[string]string{
+			causeParentMetadata:  strconv.Itoa(parent),
+			causeMessageMetadata: errorMessage(grpcSink, err),
+		},
+	})
+
+	// keep wrapped AsertoErrors reachable so their fields survive the round-trip.
+	walk(err, 0, func(inner error, _ int) bool {
+		aerr, ok := inner.(*AsertoError) //nolint:errorlint // the tree is traversed by walk.
+		if ok {
+			enc.encodeCause(index, aerr)
+		}
+
+		return !ok
+	})
+}
+
+// decodeStatus rebuilds an AsertoError from the ErrorInfo details produced by statusEncoder.
+// Errors whose code is not registered in r are synthesized from the content of the details.
+func (r *Registry) decodeStatus(grpcStatus *status.Status) *AsertoError {
+	var (
+		result *AsertoError
+		nodes  []error
+		extras statusExtras
+	)
+
+	for _, detail := range grpcStatus.Details() {
+		if extras.collect(detail) {
+			continue
+		}
+
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok {
+			continue
+		}
+
+		if result == nil {
+			result = r.decodeRoot(grpcStatus, info)
+			nodes = append(nodes, result)
+
+			continue
+		}
+
+		if info.GetReason() != causeReason {
+			continue
+		}
+
+		nodes = append(nodes, r.decodeInner(nodes, info))
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	extras.apply(result)
+
+	return result
+}
+
+// decodeRoot rebuilds the AsertoError described by the first ErrorInfo of grpcStatus.
+func (r *Registry) decodeRoot(grpcStatus *status.Status, info *errdetails.ErrorInfo) *AsertoError {
+	var result *AsertoError
+
+	if registered, ok := r.Lookup(info.GetDomain()); ok {
+		// never modify the registered error, it is shared by all callers.
+		result = registered.copy()
+	} else {
+		result = newAsertoError(info.GetDomain(), grpcStatus.Code(), runtime.HTTPStatusFromCode(grpcStatus.Code()), grpcStatus.Message())
+		result.unregistered = true
+
+		if template, ok := info.GetMetadata()[templateMetadata]; ok {
+			result.Message = template
+		}
+	}
+
+	result.StatusCode = grpcStatus.Code()
+	decodeMetadata(result, info.GetMetadata())
+
+	return result
+}
+
+// decodeInner rebuilds the inner error described by info and adds it to the inner errors of its parent,
+// referenced by its position in nodes. Inner errors with an invalid parent are attached to the root.
+func (r *Registry) decodeInner(nodes []error, info *errdetails.ErrorInfo) error {
+	node := r.decodeCause(info)
+
+	parent, err := strconv.Atoi(info.GetMetadata()[causeParentMetadata])
+	if err != nil || parent < 0 || parent >= len(nodes) {
+		parent = 0
+	}
+
+	switch p := nodes[parent].(type) {
+	case *AsertoError:
+		p.errs = append(p.errs, node)
+	case *wireError:
+		p.causes = append(p.causes, node)
+	}
+
+	return node
+}
+
+// statusExtras holds the details of a gRPC status that describe the error as a whole
+// rather than one of the errors of its tree.
+type statusExtras struct {
+	localized  *errdetails.LocalizedMessage
+	retryInfo  *errdetails.RetryInfo
+	violations []FieldViolation
+}
+
+// collect records detail if it is a LocalizedMessage, BadRequest or RetryInfo, and returns false otherwise.
+func (x *statusExtras) collect(detail any) bool {
+	switch d := detail.(type) {
+	case *errdetails.LocalizedMessage:
+		x.localized = d
+	case *errdetails.BadRequest:
+		x.violations = append(x.violations, decodeBadRequest(d)...)
+	case *errdetails.RetryInfo:
+		x.retryInfo = d
+	default:
+		return false
+	}
+
+	return true
+}
+
+// apply sets the collected details on e.
+func (x *statusExtras) apply(e *AsertoError) {
+	// the field violations of the inner errors are merged into the ones of the error itself.
+	e.violations = x.violations
+
+	// the retryability of the inner errors is merged into the one of the error itself.
+	if x.retryInfo != nil {
+		e.retryable = true
+		e.retryAfter = x.retryInfo.GetRetryDelay().AsDuration()
+	}
+
+	if x.localized != nil {
+		e.locale = x.localized.GetLocale()
+		e.localized = x.localized
+	}
+}
+
+func (r *Registry) decodeCause(info *errdetails.ErrorInfo) error {
+	metadata := info.GetMetadata()
+
+	if info.GetDomain() == "" {
+		return &wireError{msg: metadata[causeMessageMetadata]}
+	}
+
+	var result *AsertoError
+
+	if registered, ok := r.Lookup(info.GetDomain()); ok {
+		result = registered.copy()
+	} else {
+		result = newAsertoError(info.GetDomain(), codes.Unknown, 0, metadata[causeMessageMetadata])
+		result.unregistered = true
+	}
+
+	if code, err := strconv.Atoi(metadata[grpcStatusErrorMetadata]); err == nil {
+		result.StatusCode = codes.Code(code) //nolint:gosec // status codes are encoded from a codes.Code.
+	}
+
+	decodeMetadata(result, metadata)
+
+	return result
+}
+
+// decodeMetadata sets the data and HTTP status code of e from ErrorInfo metadata, skipping reserved keys.
+// The attributes are stored as strings, the typed getters of AsertoError parse them on demand.
+func decodeMetadata(e *AsertoError, metadata map[string]string) {
+	if value, ok := metadata[HTTPStatusErrorMetadata]; ok {
+		if code, err := strconv.Atoi(value); err == nil {
+			e.HTTPCode = code
+		}
+	}
+
+	data := make(map[string]any, len(metadata))
+
+	for key, value := range metadata {
+		switch key {
+		case HTTPStatusErrorMetadata, causeParentMetadata, causeMessageMetadata, grpcStatusErrorMetadata, templateMetadata, sensitiveKeysMetadata:
+			continue
+		}
+
+		data[key] = value
+	}
+
+	markSensitive(data, metadata)
+
+	e.data = attributesFromMap(data)
+}
+
+// convertStatus is like status.Convert, but the message of the status of a wrapped AsertoError,
+// which grpc-go sets to the error string of err, is rendered for sink s.
+func convertStatus(s sink, err error) *status.Status {
+	st := status.Convert(err)
+
+	var aerr *AsertoError
+	if !errors.As(err, &aerr) || err == error(aerr) { //nolint:errorlint // only wrapped AsertoErrors are rendered here.
+		return st
+	}
+
+	pb := st.Proto()
+	pb.Message = errorMessage(s, err)
+
+	return status.FromProto(pb)
+}
+
+// errorCode returns the code of err if it is an AsertoError or a gRPC status carrying one.
+func errorCode(err error) (string, bool) {
+	if aerr, ok := err.(*AsertoError); ok { //nolint:errorlint // only err itself is considered.
+		if aerr == nil {
+			return "", false
+		}
+
+		return aerr.Code, true
+	}
+
+	if grpcErr, ok := err.(grpcStatusError); ok { //nolint:errorlint // only err itself is considered.
+		return statusErrorCode(grpcErr.GRPCStatus())
+	}
+
+	return "", false
+}
+
+// wrappedStatusCode returns the code of the AsertoError carried by the first gRPC status found in the chain of err,
+// if that code is registered in the default registry.
+// It returns false if that status is an AsertoError, since those are matched by errors.Is directly.
+func wrappedStatusCode(err error) (string, bool) {
+	var grpcErr grpcStatusError
+	if !errors.As(err, &grpcErr) {
+		return "", false
+	}
+
+	if _, ok := grpcErr.(*AsertoError); ok {
+		return "", false
+	}
+
+	code, ok := statusErrorCode(grpcErr.GRPCStatus())
+	if !ok {
+		return "", false
+	}
+
+	if _, registered := defaultRegistry.Lookup(code); !registered {
+		return "", false
+	}
+
+	return code, true
+}
+
+// statusErrorCode returns the code of the AsertoError described by the first ErrorInfo of st.
+func statusErrorCode(st *status.Status) (string, bool) {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetReason() != causeReason {
+			return info.GetDomain(), true
+		}
+	}
+
+	return "", false
+}
+
+type grpcStatusError interface {
+	error
+	GRPCStatus() *status.Status
+}
+
+// wireError is a non-Aserto inner error received over the wire.
+// It keeps the original error message and the AsertoErrors it wrapped.
+type wireError struct {
+	msg    string
+	causes []error
+}
+
+func (e *wireError) Error() string {
+	return e.msg
+}
+
+func (e *wireError) Unwrap() []error {
+	return e.causes
+}
@@ -0,0 +1,131 @@
+package errors
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	placeholderStart = "{{"
+	placeholderEnd   = "}}"
+)
+
+// ErrInvalidTemplate is returned when registering an error whose message is not a valid template.
+var ErrInvalidTemplate = errors.New("invalid message template")
+
+// Placeholders returns the keys of the {{key}} placeholders of the message of the error,
+// which are rendered from the attributes with the same keys.
+func (e *AsertoError) Placeholders() []string {
+	keys, _ := placeholders(e.Message)
+
+	return keys
+}
+
+// message returns the message of the error with its placeholders replaced by the attributes of the error,
+// redacted for sink s. Placeholders without a matching attribute are left as is.
+func (e *AsertoError) message(s sink) string {
+	return expandTemplate(e.Message, e.attributeLookup(s))
+}
+
+// placeholders returns the keys of the {{key}} placeholders of template.
+// An error wrapping ErrInvalidTemplate is returned if a placeholder is not terminated or has an invalid key.
+func placeholders(template string) ([]string, error) {
+	var keys []string
+
+	for offset := 0; ; {
+		start := strings.Index(template[offset:], placeholderStart)
+		if start < 0 {
+			return keys, nil
+		}
+
+		start += offset + len(placeholderStart)
+
+		end := strings.Index(template[start:], placeholderEnd)
+		if end < 0 {
+			return keys, errors.Wrapf(ErrInvalidTemplate, "unterminated placeholder at offset %d", start-len(placeholderStart))
+		}
+
+		key := strings.TrimSpace(template[start : start+end])
+		if !validPlaceholderKey(key) {
+			return keys, errors.Wrapf(ErrInvalidTemplate, "invalid placeholder %q", template[start-len(placeholderStart):start+end+len(placeholderEnd)])
+		}
+
+		keys = append(keys, key)
+		offset = start + end + len(placeholderEnd)
+	}
+}
+
+// validPlaceholderKey returns true if key is a non-empty attribute key made of letters, digits, '_', '-' and '.'.
+func validPlaceholderKey(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// expandTemplate replaces the {{key}} placeholders of template with the values returned by lookup.
+// Placeholders for which lookup returns false are left as is.
+func expandTemplate(template string, lookup func(key string) (string, bool)) string {
+	if !strings.Contains(template, placeholderStart) {
+		return template
+	}
+
+	var sb strings.Builder
+
+	for {
+		start := strings.Index(template, placeholderStart)
+		if start < 0 {
+			break
+		}
+
+		end := strings.Index(template[start:], placeholderEnd)
+		if end < 0 {
+			break
+		}
+
+		end += start
+		placeholder := template[start : end+len(placeholderEnd)]
+
+		sb.WriteString(template[:start])
+
+		if value, ok := lookup(strings.TrimSpace(template[start+len(placeholderStart) : end])); ok {
+			sb.WriteString(value)
+		} else {
+			sb.WriteString(placeholder)
+		}
+
+		template = template[end+len(placeholderEnd):]
+	}
+
+	sb.WriteString(template)
+
+	return sb.String()
+}
+
+// attributeLookup returns a lookup function for expandTemplate resolving placeholders from the attributes of e,
+// redacted for sink s. Omitted sensitive attributes are rendered as RedactedValue.
+func (e *AsertoError) attributeLookup(s sink) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := e.data.get(key)
+		if !ok {
+			return "", false
+		}
+
+		redacted, ok := redactValue(s, key, value)
+		if !ok {
+			return RedactedValue, true
+		}
+
+		return formatValue(redacted), true
+	}
+}
@@ -0,0 +1,115 @@
+package errors_test
+
+import (
+	"net/http"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+var ErrObjectTypeNotFound = newErr("E10020", codes.NotFound, http.StatusNotFound, "object {{object_id}} of type {{ object_type }} not found")
+
+func TestTemplateRendering(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal([]string{"object_id", "object_type"}, ErrObjectTypeNotFound.Placeholders())
+	assert.Empty(ErrNotFound.Placeholders())
+
+	err := ErrObjectTypeNotFound.Str("object_id", "1234").Str("object_type", "user")
+	assert.Equal("E10020 object 1234 of type user not found", err.Error())
+	assert.Equal("object {{object_id}} of type {{ object_type }} not found", err.Message)
+
+	err = err.Msg("lookup failed").Err(ErrAlreadyExists)
+	assert.Equal("E10020 object 1234 of type user not found: lookup failed: E10002 already exists", err.Error())
+}
+
+func TestTemplateMissingAttributes(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("E10020 object {{object_id}} of type {{ object_type }} not found", ErrObjectTypeNotFound.Error())
+	assert.Equal("E10020 object 1234 of type {{ object_type }} not found", ErrObjectTypeNotFound.Int("object_id", 1234).Error())
+}
+
+func TestTemplateRegistration(t *testing.T) {
+	assert := require.New(t)
+
+	registry := cerr.NewRegistry()
+
+	_, err := registry.Register("E20001", codes.NotFound, http.StatusNotFound, "object {{object_id not found")
+	assert.True(errors.Is(err, cerr.ErrInvalidTemplate))
+	assert.Contains(err.Error(), "E20001")
+
+	_, err = registry.Register("E20002", codes.NotFound, http.StatusNotFound, "object {{}} not found")
+	assert.True(errors.Is(err, cerr.ErrInvalidTemplate))
+
+	_, err = registry.Register("E20003", codes.NotFound, http.StatusNotFound, "object {{object id}} not found")
+	assert.True(errors.Is(err, cerr.ErrInvalidTemplate))
+
+	_, ok := registry.Lookup("E20001")
+	assert.False(ok)
+
+	aerr, err := registry.Register("E20004", codes.NotFound, http.StatusNotFound, "object {{object_id}} not found")
+	assert.NoError(err)
+	assert.Equal([]string{"object_id"}, aerr.Placeholders())
+
+	assert.Panics(func() { registry.MustRegister("E20005", codes.NotFound, http.StatusNotFound, "{{") })
+	assert.True(errors.Is(cerr.NewCatalog().Add("E20004", "fr", "objet {{object_id introuvable"), cerr.ErrInvalidTemplate))
+}
+
+func TestTemplateNewAsertoError(t *testing.T) {
+	assert := require.New(t)
+
+	// NewAsertoError does not validate the message, malformed placeholders are rendered as is.
+	var aerr *cerr.AsertoError
+
+	assert.NotPanics(func() {
+		aerr = cerr.NewAsertoError("E20006", codes.NotFound, http.StatusNotFound, "object {{object_id not found")
+	})
+	assert.Equal("E20006 object {{object_id not found", aerr.Str("object_id", "1234").Error())
+}
+
+func TestTemplateGRPCStatus(t *testing.T) {
+	assert := require.New(t)
+
+	err := ErrObjectTypeNotFound.Str("object_id", "1234").Str("object_type", "user")
+	assert.Equal("object 1234 of type user not found", err.GRPCStatus().Message())
+
+	received := roundTrip(t, err)
+	assert.Equal(err.Message, received.Message)
+	assert.Equal(err.Error(), received.Error())
+
+	nested := roundTrip(t, ErrAlreadyExists.Err(err))
+	assert.Equal("E10002 already exists: E10020 object 1234 of type user not found", nested.Error())
+}
+
+func TestTemplateUnregisteredCode(t *testing.T) {
+	assert := require.New(t)
+
+	sent := cerr.NewRegistry().MustRegister("E20010", codes.NotFound, http.StatusNotFound, "tenant {{tenant}} not found").
+		Str("tenant", "acme")
+
+	received := roundTrip(t, sent)
+	assert.True(received.Unregistered())
+	assert.Equal(sent.Message, received.Message)
+	assert.Equal("E20010 tenant acme not found", received.Error())
+	assert.Equal(received.Error(), roundTrip(t, received.Str("tenant", "acme")).Error())
+}
+
+func TestTemplateRedactsSensitiveAttributes(t *testing.T) {
+	assert := require.New(t)
+
+	setRedactionPolicy(t, cerr.RedactionPolicy{Log: cerr.RedactMask, GRPC: cerr.RedactNone, HTTP: cerr.RedactMask})
+
+	err := ErrObjectTypeNotFound.Sensitive("object_id", "1234").Str("object_type", "user")
+	assert.Equal("E10020 object [REDACTED] of type user not found", err.Error())
+	assert.Equal("object 1234 of type user not found", err.GRPCStatus().Message())
+
+	resp := serveError(t, cerr.CustomErrorHandler, err.GRPCStatus().Err(), nil)
+	assert.Equal("object [REDACTED] of type user not found", readBody(t, resp)["message"])
+
+	resp = serveError(t, cerr.NewErrorHandler(cerr.WithProblemDetails()), err.GRPCStatus().Err(), nil)
+	assert.Equal("object [REDACTED] of type user not found", readBody(t, resp)["title"])
+}
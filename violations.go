@@ -0,0 +1,104 @@
+package errors
+
+import (
+	"slices"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+const (
+	// FieldViolationsKey is the name of the field holding the field violations of an error in logs.
+	FieldViolationsKey = "field_violations"
+
+	// ProblemInvalidParamsMember is the problem details extension member listing the field violations of an error.
+	ProblemInvalidParamsMember = "invalid-params"
+)
+
+// FieldViolation describes an invalid field of a request.
+type FieldViolation struct {
+	// Field is the path of the invalid field, e.g. "object.properties.email".
+	Field string
+	// Description explains why the field is invalid.
+	Description string
+}
+
+// FieldViolation returns a copy of the error with an additional field violation.
+// The field violations are sent as errdetails.BadRequest in the gRPC status of the error.
+func (e *AsertoError) FieldViolation(field, description string) *AsertoError {
+	c := e.Copy()
+	c.violations = append(slices.Clip(c.violations), FieldViolation{Field: field, Description: description})
+
+	return c
+}
+
+// FieldViolations returns the field violations of the error and of all the AsertoErrors
+// found in the tree of its inner errors, in the order they were added.
+func (e *AsertoError) FieldViolations() []FieldViolation {
+	var result []FieldViolation
+
+	walk(e, 0, func(err error, _ int) bool {
+		if aerr, ok := err.(*AsertoError); ok { //nolint:errorlint // the tree is traversed by walk.
+			result = append(result, aerr.violations...)
+		}
+
+		return true
+	})
+
+	return result
+}
+
+// badRequest returns the field violations of the error as errdetails.BadRequest, or nil if there are none.
+func (e *AsertoError) badRequest() *errdetails.BadRequest {
+	violations := e.FieldViolations()
+	if len(violations) == 0 {
+		return nil
+	}
+
+	badRequest := &errdetails.BadRequest{
+		FieldViolations: make([]*errdetails.BadRequest_FieldViolation, len(violations)),
+	}
+
+	for i, v := range violations {
+		badRequest.FieldViolations[i] = &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		}
+	}
+
+	return badRequest
+}
+
+// decodeBadRequest returns the field violations of an errdetails.BadRequest.
+func decodeBadRequest(badRequest *errdetails.BadRequest) []FieldViolation {
+	violations := make([]FieldViolation, len(badRequest.GetFieldViolations()))
+
+	for i, v := range badRequest.GetFieldViolations() {
+		violations[i] = FieldViolation{Field: v.GetField(), Description: v.GetDescription()}
+	}
+
+	return violations
+}
+
+// problemInvalidParams returns the field violations in the format of the "invalid-params" member of RFC 9457.
+func problemInvalidParams(violations []FieldViolation) []map[string]string {
+	params := make([]map[string]string, len(violations))
+
+	for i, v := range violations {
+		params[i] = map[string]string{"name": v.Field, "reason": v.Description}
+	}
+
+	return params
+}
+
+// fieldViolations logs field violations as an array of objects.
+type fieldViolations []FieldViolation
+
+func (v fieldViolations) MarshalZerologArray(a *zerolog.Array) {
+	for _, violation := range v {
+		a.Dict(zerolog.Dict().
+			Str("field", violation.Field).
+			Str("description", violation.Description),
+		)
+	}
+}
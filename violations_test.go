@@ -0,0 +1,116 @@
+package errors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+var ErrInvalidArgument = newErr("E10030", codes.InvalidArgument, http.StatusBadRequest, "invalid argument")
+
+func invalidUser() *cerr.AsertoError {
+	return ErrInvalidArgument.
+		FieldViolation("user.email", "must be a valid email address").
+		FieldViolation("user.name", "must not be empty")
+}
+
+func TestFieldViolations(t *testing.T) {
+	assert := require.New(t)
+
+	err := invalidUser()
+	assert.Equal([]cerr.FieldViolation{
+		{Field: "user.email", Description: "must be a valid email address"},
+		{Field: "user.name", Description: "must not be empty"},
+	}, err.FieldViolations())
+	assert.Empty(ErrInvalidArgument.FieldViolations())
+
+	// builders never modify the error they are called on.
+	first := ErrInvalidArgument.FieldViolation("a", "invalid")
+	second := first.FieldViolation("b", "invalid")
+	third := first.FieldViolation("c", "invalid")
+	assert.Len(first.FieldViolations(), 1)
+	assert.Equal("b", second.FieldViolations()[1].Field)
+	assert.Equal("c", third.FieldViolations()[1].Field)
+
+	wrapped := cerr.ErrUnknown.Err(err).FieldViolation("user.id", "must be a UUID")
+	assert.Len(wrapped.FieldViolations(), 3)
+	assert.Equal("user.id", wrapped.FieldViolations()[0].Field)
+}
+
+func TestFieldViolationsGRPCStatus(t *testing.T) {
+	assert := require.New(t)
+
+	err := invalidUser()
+
+	var badRequest *errdetails.BadRequest
+
+	for _, detail := range err.GRPCStatus().Details() {
+		if d, ok := detail.(*errdetails.BadRequest); ok {
+			badRequest = d
+		}
+	}
+
+	assert.NotNil(badRequest)
+	assert.Len(badRequest.GetFieldViolations(), 2)
+	assert.Equal("user.email", badRequest.GetFieldViolations()[0].GetField())
+	assert.Equal("must be a valid email address", badRequest.GetFieldViolations()[0].GetDescription())
+
+	received := roundTrip(t, err)
+	assert.Equal(err.FieldViolations(), received.FieldViolations())
+	assert.Equal(err.FieldViolations(), roundTrip(t, received).FieldViolations())
+
+	nested := roundTrip(t, ErrAlreadyExists.Err(err))
+	assert.Equal(err.FieldViolations(), nested.FieldViolations())
+}
+
+func TestFieldViolationsLogged(t *testing.T) {
+	assert := require.New(t)
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf)
+	logger.Error().EmbedObject(invalidUser()).Send()
+
+	entry := struct {
+		Violations []map[string]string `json:"field_violations"`
+	}{}
+	assert.NoError(json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal([]map[string]string{
+		{"field": "user.email", "description": "must be a valid email address"},
+		{"field": "user.name", "description": "must not be empty"},
+	}, entry.Violations)
+}
+
+func TestCustomErrorHandlerFieldViolations(t *testing.T) {
+	assert := require.New(t)
+
+	err := invalidUser().GRPCStatus().Err()
+
+	resp := serveError(t, cerr.CustomErrorHandler, err, nil)
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	var violations []any
+
+	for _, detail := range readBody(t, resp)["details"].([]any) {
+		if d := detail.(map[string]any); d["@type"] == "type.googleapis.com/google.rpc.BadRequest" {
+			violations, _ = d["fieldViolations"].([]any)
+		}
+	}
+
+	assert.Len(violations, 2)
+	assert.Equal("user.email", violations[0].(map[string]any)["field"])
+	assert.Equal("must not be empty", violations[1].(map[string]any)["description"])
+
+	resp = serveError(t, cerr.NewErrorHandler(cerr.WithProblemDetails()), err, nil)
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+	assert.Equal([]any{
+		map[string]any{"name": "user.email", "reason": "must be a valid email address"},
+		map[string]any{"name": "user.name", "reason": "must not be empty"},
+	}, readBody(t, resp)[cerr.ProblemInvalidParamsMember])
+}